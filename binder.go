@@ -5,59 +5,170 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"reflect"
-	"unsafe"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iancoleman/strcase"
 )
 
-// makeNewPointersOf creates a map of [field name] -> pointer to fill it
-// recursively. it will go down until reaches a driver.Valuer implementation, it will stop there.
+// nestedColumnSeparator separates name of a nested struct field from its own column names,
+// so a joined column selected as author__name is bound to Author.Name.
+const nestedColumnSeparator = "__"
+
+// makeNewPointersOf creates a map of [column name] -> pointer to field of struct v, fields inside
+// nil nested struct pointers are left out. for types that are not nested structs it returns
+// pointer to v itself.
 func (b *binder) makeNewPointersOf(v reflect.Value) interface{} {
+	if !isNestedStruct(v.Type()) {
+		return v.Addr().Interface()
+	}
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
 	m := map[string]interface{}{}
-	actualV := v
-	for actualV.Type().Kind() == reflect.Ptr {
-		actualV = actualV.Elem()
-	}
-	if actualV.Type().Kind() == reflect.Struct {
-		for i := 0; i < actualV.NumField(); i++ {
-			f := actualV.Field(i)
-			if (f.Type().Kind() == reflect.Struct || f.Type().Kind() == reflect.Ptr) && !f.Type().Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem()) {
-				f = reflect.NewAt(actualV.Type().Field(i).Type, unsafe.Pointer(actualV.Field(i).UnsafeAddr()))
-				fm := b.makeNewPointersOf(f).(map[string]interface{})
-				for k, p := range fm {
-					m[k] = p
-				}
-			} else {
-				var fm *field
-				fm = b.s.getField(actualV.Type().Field(i))
-				if fm == nil {
-					fm = fieldMetadata(actualV.Type().Field(i), b.s.columnConstraints)[0]
-				}
-				m[fm.Name] = reflect.NewAt(actualV.Field(i).Type(), unsafe.Pointer(actualV.Field(i).UnsafeAddr())).Interface()
+	for name, fm := range b.columnIndex(v.Type()) {
+		if f, ok := fieldByPath(v, fm.Path, false); ok {
+			m[name] = f.Addr().Interface()
+		}
+	}
+	return m
+}
+
+type columnIndexKey struct {
+	t reflect.Type
+	s *schema
+}
+
+// columnIndexes caches columnIndex of each type and schema pair.
+var columnIndexes sync.Map
+
+// columnIndex returns fields of struct type t by the column they are bound from, it's
+// built once from the same field metadata schemas use. columns of a named nested struct field are
+// also available prefixed with field name and nestedColumnSeparator, so they don't collide with
+// columns of the outer struct, unprefixed names are kept as long as they don't shadow a column
+// of an outer struct.
+func (b *binder) columnIndex(t reflect.Type) map[string]*field {
+	key := columnIndexKey{t: t, s: b.s}
+	if index, exists := columnIndexes.Load(key); exists {
+		return index.(map[string]*field)
+	}
+	var fieldConfigurators []*FieldConfigurator
+	if b.s != nil {
+		fieldConfigurators = b.s.columnConstraints
+	}
+	fms := fieldsOfType(t, fieldConfigurators)
+	if b.s != nil {
+		bindEncryptedColumns(b.s.Table, fms)
+	}
+	index := map[string]*field{}
+	// rank of a name is number of nested struct names left out of it, lower rank wins.
+	ranks := map[string]int{}
+	for _, fm := range fms {
+		if fm.Virtual || fm.IsBlindIndex {
+			continue
+		}
+		name, prefixes := columnName(t, fm)
+		if name == "" {
+			continue
+		}
+		for rank := 0; rank <= len(prefixes); rank++ {
+			column := strings.Join(append(slices.Clone(prefixes[rank:]), name), nestedColumnSeparator)
+			if r, exists := ranks[column]; exists && r <= rank {
+				continue
 			}
+			index[column] = fm
+			ranks[column] = rank
 		}
-	} else {
-		return v.Addr().Interface()
 	}
+	columnIndexes.Store(key, index)
+	return index
+}
 
-	return m
+// columnName returns column that field fm of struct type t is bound from and snake case names of
+// named nested structs it's in. db tag of field is used if it has one and empty name means field
+// should not be bound, otherwise it's the name field has in schema.
+func columnName(t reflect.Type, fm *field) (string, []string) {
+	var prefixes []string
+	var sf reflect.StructField
+	for i, idx := range fm.Path {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		sf = t.Field(idx)
+		if tag, _ := sf.Tag.Lookup("db"); strings.Split(tag, ",")[0] == "-" {
+			return "", nil
+		}
+		if i < len(fm.Path)-1 {
+			if !sf.Anonymous {
+				prefixes = append(prefixes, strcase.ToSnake(sf.Name))
+			}
+			t = sf.Type
+		}
+	}
+	if tag, hasTag := sf.Tag.Lookup("db"); hasTag {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name, prefixes
+		}
+	}
+	return fm.Name, prefixes
+}
+
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// isNestedStruct reports whether binder should go into fields of t instead of scanning
+// a column into it, types that database/sql can scan into like time.Time or sql.Scanner
+// implementations are scanned as a single column.
+func isNestedStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		if t.Implements(valuerType) || t.Implements(scannerType) {
+			return false
+		}
+		t = t.Elem()
+	}
+	if t == timeType || t.Implements(valuerType) || reflect.PointerTo(t).Implements(scannerType) {
+		return false
+	}
+	return t.Kind() == reflect.Struct
 }
 
-// ptrsFor first allocates for all struct fields recursively until reaches a driver.Value impl
-// then it will put them in a map with their correct field name as key, then loops over cts
-// and for each one gets appropriate one from the map and adds it to pointer list.
+// ptrsFor returns pointers to scan columns of cts into, for structs each column is scanned into
+// the field bound from it, through its converter or JSON decoding if field has one. nil nested
+// struct pointers are allocated on the way and columns that have no field are scanned into a
+// throwaway value.
 func (b *binder) ptrsFor(v reflect.Value, cts []*sql.ColumnType) []interface{} {
-	ptrs := b.makeNewPointersOf(v)
+	if !isNestedStruct(v.Type()) {
+		return []interface{}{v.Addr().Interface()}
+	}
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	index := b.columnIndex(v.Type())
 	var scanInto []interface{}
-	if reflect.TypeOf(ptrs).Kind() == reflect.Map {
-		nameToPtr := ptrs.(map[string]interface{})
-		for _, ct := range cts {
-			if nameToPtr[ct.Name()] != nil {
-				scanInto = append(scanInto, nameToPtr[ct.Name()])
+	for _, ct := range cts {
+		if fm, exists := index[ct.Name()]; exists {
+			if f, ok := fieldByPath(v, fm.Path, true); ok {
+				switch {
+				case fm.Converter != nil:
+					scanInto = append(scanInto, converterScanner{c: fm.Converter, dst: f})
+				case fm.JSON:
+					scanInto = append(scanInto, jsonScanner{dst: f})
+				default:
+					scanInto = append(scanInto, f.Addr().Interface())
+				}
+				continue
 			}
 		}
-	} else {
-		scanInto = append(scanInto, ptrs)
+		// columns without a field are scanned and dropped so
+		// structs can have fewer fields than selected columns.
+		scanInto = append(scanInto, new(interface{}))
 	}
-
 	return scanInto
 }
 
@@ -69,8 +180,44 @@ func newBinder(s *schema) *binder {
 	return &binder{s: s}
 }
 
-// bind binds given rows to the given object at obj. obj should be a pointer
+// newBinderFor creates a binder for T, s is only used when T is an entity so other
+// structs are bound using their own field names and db tags.
+func newBinderFor[T any](s *schema) *binder {
+	var zero T
+	if _, isEntity := any(zero).(Entity); isEntity {
+		return newBinder(s)
+	}
+	if _, isEntity := any(&zero).(Entity); isEntity {
+		return newBinder(s)
+	}
+	return newBinder(nil)
+}
+
+// bindRow scans current row of rows into a new value of type t, t can be
+// a pointer type too.
+func (b *binder) bindRow(rows *sql.Rows, cts []*sql.ColumnType, t reflect.Type) (reflect.Value, error) {
+	// Since reflect.New returns a pointer to the type, we need to unwrap it to get actual
+	rowValue := reflect.New(t).Elem()
+	// till we reach a not pointer type continue newing the underlying type.
+	for rowValue.IsZero() && rowValue.Type().Kind() == reflect.Ptr {
+		rowValue = reflect.New(rowValue.Type().Elem()).Elem()
+	}
+	ptrs := b.ptrsFor(rowValue, cts)
+	if err := rows.Scan(ptrs...); err != nil {
+		return reflect.Value{}, err
+	}
+	for rowValue.Type() != t {
+		tmp := reflect.New(rowValue.Type())
+		tmp.Elem().Set(rowValue)
+		rowValue = tmp
+	}
+	return rowValue, nil
+}
+
+// bind binds given rows to the given object at obj. obj should be a pointer,
+// if obj is not a slice and there is no row ErrNotFound is returned.
 func (b *binder) bind(rows *sql.Rows, obj interface{}) error {
+	defer rows.Close()
 	cts, err := rows.ColumnTypes()
 	if err != nil {
 		return err
@@ -88,51 +235,56 @@ func (b *binder) bind(rows *sql.Rows, obj interface{}) error {
 		// getting slice elemnt type -> slice[t]
 		t = t.Elem()
 		for rows.Next() {
-			var rowValue reflect.Value
-			// Since reflect.SetupConnections returns a pointer to the type, we need to unwrap it to get actual
-			rowValue = reflect.New(t).Elem()
-			// till we reach a not pointer type continue newing the underlying type.
-			for rowValue.IsZero() && rowValue.Type().Kind() == reflect.Ptr {
-				rowValue = reflect.New(rowValue.Type().Elem()).Elem()
-			}
-			newCts := make([]*sql.ColumnType, len(cts))
-			copy(newCts, cts)
-			ptrs := b.ptrsFor(rowValue, newCts)
-			err = rows.Scan(ptrs...)
+			rowValue, err := b.bindRow(rows, cts, t)
 			if err != nil {
 				return err
 			}
-			for rowValue.Type() != t {
-				tmp := reflect.New(rowValue.Type())
-				tmp.Elem().Set(rowValue)
-				rowValue = tmp
-			}
 			v = reflect.Append(v, rowValue)
 		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
 	} else {
+		var found bool
 		for rows.Next() {
+			found = true
+			// allocate pointer outputs like *Post so there is a struct to scan into.
+			for target := v; target.Kind() == reflect.Ptr; target = target.Elem() {
+				if target.IsNil() {
+					target.Set(reflect.New(target.Type().Elem()))
+				}
+			}
 			ptrs := b.ptrsFor(v, cts)
 			err = rows.Scan(ptrs...)
 			if err != nil {
 				return err
 			}
 		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if !found {
+			return ErrNotFound
+		}
 	}
 	// v is either struct or slice
 	reflect.ValueOf(obj).Elem().Set(v)
 	return nil
 }
 
+// bindToMap binds each row of rows into a map of column name to its value, values are
+// normalized using normalizeMapValue.
 func bindToMap(rows *sql.Rows) ([]map[string]interface{}, error) {
+	defer rows.Close()
 	cts, err := rows.ColumnTypes()
 	if err != nil {
 		return nil, err
 	}
 	var ms []map[string]interface{}
 	for rows.Next() {
-		var ptrs []interface{}
-		for _, ct := range cts {
-			ptrs = append(ptrs, reflect.New(ct.ScanType()).Interface())
+		ptrs := make([]interface{}, len(cts))
+		for i := range cts {
+			ptrs[i] = new(interface{})
 		}
 
 		err = rows.Scan(ptrs...)
@@ -141,10 +293,37 @@ func bindToMap(rows *sql.Rows) ([]map[string]interface{}, error) {
 		}
 		m := map[string]interface{}{}
 		for i, ptr := range ptrs {
-			m[cts[i].Name()] = reflect.ValueOf(ptr).Elem().Interface()
+			m[cts[i].Name()] = normalizeMapValue(cts[i].DatabaseTypeName(), *ptr.(*interface{}))
 		}
 
 		ms = append(ms, m)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
 	return ms, nil
 }
+
+// normalizeMapValue converts values drivers return as []byte, like text columns or
+// numbers in MySQL, into string, int64 or float64 based on column database type,
+// binary columns are kept as []byte.
+func normalizeMapValue(databaseType string, v interface{}) interface{} {
+	bs, isBytes := v.([]byte)
+	if !isBytes {
+		return v
+	}
+	databaseType = strings.ToUpper(databaseType)
+	switch {
+	case strings.Contains(databaseType, "BLOB"), strings.Contains(databaseType, "BINARY"), databaseType == "BYTEA":
+		return bs
+	case strings.Contains(databaseType, "INT"):
+		if n, err := strconv.ParseInt(string(bs), 10, 64); err == nil {
+			return n
+		}
+	case databaseType == "FLOAT", databaseType == "DOUBLE", databaseType == "REAL":
+		if f, err := strconv.ParseFloat(string(bs), 64); err == nil {
+			return f
+		}
+	}
+	return string(bs)
+}
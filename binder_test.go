@@ -38,7 +38,8 @@ func TestBind(t *testing.T) {
 		assert.NoError(t, err)
 
 		u := &User{}
-		md := schemaOfHeavyReflectionStuff(u)
+		md, err := schemaOfHeavyReflectionStuff(u)
+		assert.NoError(t, err)
 		err = newBinder(md).bind(rows, u)
 		assert.NoError(t, err)
 
@@ -55,7 +56,8 @@ func TestBind(t *testing.T) {
 		rows, err := db.Query(`SELECT * FROM users`)
 		assert.NoError(t, err)
 
-		md := schemaOfHeavyReflectionStuff(&User{})
+		md, err := schemaOfHeavyReflectionStuff(&User{})
+		assert.NoError(t, err)
 		var users []*User
 		err = newBinder(md).bind(rows, &users)
 		assert.NoError(t, err)
@@ -65,6 +67,62 @@ func TestBind(t *testing.T) {
 	})
 }
 
+type UserWithAddress struct {
+	User
+	Address Address
+}
+
+func TestBindNested(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	mock.
+		ExpectQuery("SELECT .* FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "address__id", "address__path"}).AddRow(1, "amirreza", 2, "tehran"))
+	rows, err := db.Query(`SELECT * FROM users`)
+	assert.NoError(t, err)
+
+	md, err := schemaOfHeavyReflectionStuff(&User{})
+	assert.NoError(t, err)
+	u := &UserWithAddress{}
+	err = newBinder(md).bind(rows, u)
+	assert.NoError(t, err)
+
+	assert.EqualValues(t, 1, u.ID)
+	assert.Equal(t, "amirreza", u.Name)
+	assert.EqualValues(t, 2, u.Address.ID)
+	assert.Equal(t, "tehran", u.Address.Path)
+}
+
+type Profile struct {
+	Bio string
+}
+
+type UserWithProfile struct {
+	*Timestamps
+	ID      int64
+	Profile *Profile
+}
+
+func TestBindEmbeddedPointers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	now := time.Now()
+	mock.
+		ExpectQuery("SELECT .* FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "profile__bio"}).AddRow(1, now, "gopher"))
+	rows, err := db.Query(`SELECT * FROM users`)
+	assert.NoError(t, err)
+
+	var us []UserWithProfile
+	err = newBinder(nil).bind(rows, &us)
+	assert.NoError(t, err)
+
+	assert.Len(t, us, 1)
+	assert.EqualValues(t, 1, us[0].ID)
+	assert.Equal(t, sql.NullTime{Time: now, Valid: true}, us[0].CreatedAt)
+	assert.Equal(t, "gopher", us[0].Profile.Bio)
+}
+
 func TestBindMap(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -82,3 +140,14 @@ func TestBindMap(t *testing.T) {
 
 	assert.Len(t, ms, 1)
 }
+
+func TestNormalizeMapValue(t *testing.T) {
+	assert.Equal(t, "amirreza", normalizeMapValue("VARCHAR", []byte("amirreza")))
+	assert.Equal(t, []byte{1, 2}, normalizeMapValue("BLOB", []byte{1, 2}))
+	assert.Equal(t, []byte{1, 2}, normalizeMapValue("BYTEA", []byte{1, 2}))
+	assert.EqualValues(t, 12, normalizeMapValue("BIGINT", []byte("12")))
+	assert.EqualValues(t, 1.5, normalizeMapValue("DOUBLE", []byte("1.5")))
+	assert.Equal(t, "1.50", normalizeMapValue("DECIMAL", []byte("1.50")))
+	assert.EqualValues(t, 12, normalizeMapValue("INTEGER", int64(12)))
+	assert.Nil(t, normalizeMapValue("TEXT", nil))
+}
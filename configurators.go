@@ -2,6 +2,7 @@ package orm
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/gertd/go-pluralize"
 )
@@ -11,7 +12,7 @@ type EntityConfigurator struct {
 	table             string
 	this              Entity
 	relations         map[string]interface{}
-	resolveRelations  []func()
+	resolveRelations  []func() error
 	columnConstraints []*FieldConfigurator
 }
 
@@ -33,10 +34,10 @@ func (ec *EntityConfigurator) HasMany(property Entity, config HasManyConfig) *En
 	if ec.relations == nil {
 		ec.relations = map[string]interface{}{}
 	}
-	ec.resolveRelations = append(ec.resolveRelations, func() {
+	ec.resolveRelations = append(ec.resolveRelations, func() error {
 		if config.PropertyForeignKey != "" && config.PropertyTable != "" {
 			ec.relations[config.PropertyTable] = config
-			return
+			return nil
 		}
 		configurator := newEntityConfigurator()
 		property.ConfigureEntity(configurator)
@@ -51,7 +52,7 @@ func (ec *EntityConfigurator) HasMany(property Entity, config HasManyConfig) *En
 
 		ec.relations[configurator.table] = config
 
-		return
+		return nil
 	})
 	return ec
 }
@@ -60,10 +61,10 @@ func (ec *EntityConfigurator) HasOne(property Entity, config HasOneConfig) *Enti
 	if ec.relations == nil {
 		ec.relations = map[string]interface{}{}
 	}
-	ec.resolveRelations = append(ec.resolveRelations, func() {
+	ec.resolveRelations = append(ec.resolveRelations, func() error {
 		if config.PropertyForeignKey != "" && config.PropertyTable != "" {
 			ec.relations[config.PropertyTable] = config
-			return
+			return nil
 		}
 
 		configurator := newEntityConfigurator()
@@ -77,7 +78,7 @@ func (ec *EntityConfigurator) HasOne(property Entity, config HasOneConfig) *Enti
 		}
 
 		ec.relations[configurator.table] = config
-		return
+		return nil
 	})
 	return ec
 }
@@ -86,10 +87,10 @@ func (ec *EntityConfigurator) BelongsTo(owner Entity, config BelongsToConfig) *E
 	if ec.relations == nil {
 		ec.relations = map[string]interface{}{}
 	}
-	ec.resolveRelations = append(ec.resolveRelations, func() {
+	ec.resolveRelations = append(ec.resolveRelations, func() error {
 		if config.ForeignColumnName != "" && config.LocalForeignKey != "" && config.OwnerTable != "" {
 			ec.relations[config.OwnerTable] = config
-			return
+			return nil
 		}
 		ownerConfigurator := newEntityConfigurator()
 		owner.ConfigureEntity(ownerConfigurator)
@@ -103,6 +104,7 @@ func (ec *EntityConfigurator) BelongsTo(owner Entity, config BelongsToConfig) *E
 			config.ForeignColumnName = "id"
 		}
 		ec.relations[ownerConfigurator.table] = config
+		return nil
 	})
 	return ec
 }
@@ -111,7 +113,7 @@ func (ec *EntityConfigurator) BelongsToMany(owner Entity, config BelongsToManyCo
 	if ec.relations == nil {
 		ec.relations = map[string]interface{}{}
 	}
-	ec.resolveRelations = append(ec.resolveRelations, func() {
+	ec.resolveRelations = append(ec.resolveRelations, func() error {
 		ownerConfigurator := newEntityConfigurator()
 		owner.ConfigureEntity(ownerConfigurator)
 
@@ -129,7 +131,7 @@ func (ec *EntityConfigurator) BelongsToMany(owner Entity, config BelongsToManyCo
 			config.OwnerTable = ownerConfigurator.table
 		}
 		if config.IntermediateTable == "" {
-			panic("cannot infer intermediate table yet")
+			return fmt.Errorf("cannot infer intermediate table of %s and %s yet, set IntermediateTable in BelongsToManyConfig", ec.table, ownerConfigurator.table)
 		}
 		if config.IntermediatePropertyID == "" {
 			config.IntermediatePropertyID = pluralize.NewClient().Singular(ownerConfigurator.table) + "_id"
@@ -139,6 +141,7 @@ func (ec *EntityConfigurator) BelongsToMany(owner Entity, config BelongsToManyCo
 		}
 
 		ec.relations[ownerConfigurator.table] = config
+		return nil
 	})
 	return ec
 }
@@ -151,6 +154,11 @@ type FieldConfigurator struct {
 	isCreatedAt bool
 	isUpdatedAt bool
 	isDeletedAt bool
+	virtual     bool
+	json        bool
+	prefix      string
+	converter   Converter
+	encryption  *encryption
 }
 
 func (ec *EntityConfigurator) Field(name string) *FieldConfigurator {
@@ -179,7 +187,72 @@ func (fc *FieldConfigurator) IsDeletedAt() *FieldConfigurator {
 	return fc
 }
 
+// IsVirtual marks field as not persisted, like a field holding a relation or a computed value,
+// it's not inserted, updated, selected or bound from query results.
+func (fc *FieldConfigurator) IsVirtual() *FieldConfigurator {
+	fc.virtual = true
+	return fc
+}
+
+// IsJSON stores field, which can be a struct, map or slice, as JSON in a single column.
+func (fc *FieldConfigurator) IsJSON() *FieldConfigurator {
+	fc.json = true
+	return fc
+}
+
+// Converter sets c as converter of field values, it takes precedence over converter registered for field type.
+func (fc *FieldConfigurator) Converter(c Converter) *FieldConfigurator {
+	fc.converter = c
+	return fc
+}
+
+// Encrypted stores values of field, which should be a string or []byte, encrypted with AES-GCM using
+// current key of keys, values are decrypted when they are read.
+func (fc *FieldConfigurator) Encrypted(keys KeyProvider) *FieldConfigurator {
+	if fc.encryption == nil {
+		fc.encryption = &encryption{}
+	}
+	fc.encryption.keys = keys
+	return fc
+}
+
+// BlindIndex writes HMAC-SHA256 of values of an encrypted field, keyed by key, into column too, so
+// Where conditions using =, != and IN on encrypted column work by comparing blind indexes instead.
+// blind indexes are deterministic so unlike encryption keys, key cannot be rotated without rewriting them.
+func (fc *FieldConfigurator) BlindIndex(column string, key []byte) *FieldConfigurator {
+	if fc.encryption == nil {
+		fc.encryption = &encryption{}
+	}
+	fc.encryption.blindIndex = column
+	fc.encryption.blindIndexKey = key
+	return fc
+}
+
 func (fc *FieldConfigurator) ColumnName(name string) *FieldConfigurator {
 	fc.column = name
 	return fc
 }
+
+// Nullable sets whether column of field can be NULL, it overrides nullable option of field orm tag.
+func (fc *FieldConfigurator) Nullable(nullable bool) *FieldConfigurator {
+	fc.nullable = sql.NullBool{Bool: nullable, Valid: true}
+	return fc
+}
+
+// EmbeddedConfigurator configures how fields of a nested struct field, like a value object
+// embedded in more than one entity, are mapped to columns.
+type EmbeddedConfigurator struct {
+	fc *FieldConfigurator
+}
+
+// Embedded configures nested struct field with given name.
+func (ec *EntityConfigurator) Embedded(name string) *EmbeddedConfigurator {
+	return &EmbeddedConfigurator{fc: ec.Field(name)}
+}
+
+// Prefix is added to column names of all fields of nested struct, so BillingAddress and
+// ShippingAddress of same type can have their own columns like billing_city and shipping_city.
+func (e *EmbeddedConfigurator) Prefix(prefix string) *EmbeddedConfigurator {
+	e.fc.prefix = prefix
+	return e
+}
@@ -1,19 +1,22 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jedib0t/go-pretty/table"
 )
 
 type connection struct {
-	Name                    string
-	Dialect                 *Dialect
-	DB                      *sql.DB
-	Schemas                 map[string]*schema
-	DBSchema                map[string][]columnSpec
+	Name                string
+	Dialect             *Dialect
+	DB                  *sql.DB
+	Schemas             map[string]*schema
+	DBSchema            map[string][]columnSpec
 	DatabaseValidations bool
+	interceptors        []Interceptor
 }
 
 func (c *connection) inferedTables() []string {
@@ -43,10 +46,16 @@ func (c *connection) validateTablesSchemas() error {
 	for table, sc := range c.Schemas {
 		if columns, exists := c.DBSchema[table]; exists {
 			for _, f := range sc.fields {
+				if f.Virtual {
+					continue
+				}
 				found := false
 				for _, c := range columns {
 					if c.Name == f.Name {
 						found = true
+						if f.Nullable && !c.Nullable && !c.IsPrimaryKey {
+							return fmt.Errorf("field of column %s.%s is nullable but column is NOT NULL", table, f.Name)
+						}
 					}
 				}
 				if !found {
@@ -103,9 +112,9 @@ func (c *connection) Schematic() {
 	for t, schema := range c.Schemas {
 		fmt.Printf("t: %s\n", t)
 		w := table.NewWriter()
-		w.AppendHeader(table.Row{"SQL Name", "Type", "Is Primary Key", "Is Virtual"})
+		w.AppendHeader(table.Row{"SQL Name", "Type", "Is Primary Key", "Is Virtual", "Is Nullable"})
 		for _, field := range schema.fields {
-			w.AppendRow(table.Row{field.Name, field.Type, field.IsPK, field.Virtual})
+			w.AppendRow(table.Row{field.Name, field.Type, field.IsPK, field.Virtual, field.Nullable})
 		}
 		fmt.Println(w.Render())
 		for _, rel := range schema.relations {
@@ -140,14 +149,55 @@ func GetConnection(name string) *connection {
 	return globalConnections[name]
 }
 
-func (c *connection) exec(q string, args ...any) (sql.Result, error) {
-	return c.DB.Exec(q, args...)
+// AddInterceptor registers given interceptors on connection, they will receive
+// every query that runs on this connection.
+func (c *connection) AddInterceptor(interceptors ...Interceptor) {
+	c.interceptors = append(c.interceptors, interceptors...)
 }
 
-func (c *connection) query(q string, args ...any) (*sql.Rows, error) {
-	return c.DB.Query(q, args...)
+func (c *connection) exec(ctx context.Context, table string, q string, args ...any) (sql.Result, error) {
+	args, err := driverArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	event := c.newQueryEvent(table, q, args)
+	ctx = c.beforeQuery(ctx, event)
+	start := time.Now()
+	var res sql.Result
+	if tx := c.txFromContext(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, q, args...)
+	} else {
+		res, err = c.DB.ExecContext(ctx, q, args...)
+	}
+	event.Duration = time.Since(start)
+	err = c.Dialect.translateError(err)
+	event.Err = err
+	if err == nil {
+		if affected, err := res.RowsAffected(); err == nil {
+			event.RowsAffected = affected
+		}
+	}
+	c.afterQuery(ctx, event)
+	return res, err
 }
 
-func (c *connection) queryRow(q string, args ...any) *sql.Row {
-	return c.DB.QueryRow(q, args...)
+func (c *connection) query(ctx context.Context, table string, q string, args ...any) (*sql.Rows, error) {
+	args, err := driverArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	event := c.newQueryEvent(table, q, args)
+	ctx = c.beforeQuery(ctx, event)
+	start := time.Now()
+	var rows *sql.Rows
+	if tx := c.txFromContext(ctx); tx != nil {
+		rows, err = tx.QueryContext(ctx, q, args...)
+	} else {
+		rows, err = c.DB.QueryContext(ctx, q, args...)
+	}
+	event.Duration = time.Since(start)
+	err = c.Dialect.translateError(err)
+	event.Err = err
+	c.afterQuery(ctx, event)
+	return rows, err
 }
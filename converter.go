@@ -0,0 +1,164 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Converter converts value of a field into a column value when it's written and back
+// when it's read, it's used instead of driver.Valuer and sql.Scanner of field type.
+type Converter interface {
+	// ToColumn converts v, which is value of field, into value sent to database.
+	ToColumn(v interface{}) (driver.Value, error)
+	// FromColumn converts src, a value scanned from database that is nil for NULL,
+	// into a value assignable to field.
+	FromColumn(src interface{}) (interface{}, error)
+}
+
+type funcConverter[T any] struct {
+	toColumn   func(T) (driver.Value, error)
+	fromColumn func(src interface{}) (T, error)
+}
+
+func (c funcConverter[T]) ToColumn(v interface{}) (driver.Value, error) {
+	t, ok := v.(T)
+	if !ok {
+		return nil, fmt.Errorf("converter of %s cannot convert %T", reflect.TypeOf((*T)(nil)).Elem(), v)
+	}
+	return c.toColumn(t)
+}
+
+func (c funcConverter[T]) FromColumn(src interface{}) (interface{}, error) {
+	return c.fromColumn(src)
+}
+
+// NewConverter creates a Converter for fields of type T from a pair of functions.
+func NewConverter[T any](toColumn func(T) (driver.Value, error), fromColumn func(src interface{}) (T, error)) Converter {
+	return funcConverter[T]{toColumn: toColumn, fromColumn: fromColumn}
+}
+
+// converters keeps converters registered for each type.
+var converters sync.Map
+
+// RegisterConverter sets c as converter of all fields of type T, converters set using
+// FieldConfigurator.Converter take precedence. converters should be registered before
+// ORM is set up because schemas of entities are created only once.
+func RegisterConverter[T any](c Converter) {
+	converters.Store(reflect.TypeOf((*T)(nil)).Elem(), c)
+}
+
+func converterOf(t reflect.Type) Converter {
+	c, exists := converters.Load(t)
+	if !exists {
+		return nil
+	}
+	return c.(Converter)
+}
+
+// convertedValue is written to a column using converter c.
+type convertedValue struct {
+	c Converter
+	v reflect.Value
+}
+
+func (cv convertedValue) Value() (driver.Value, error) {
+	return cv.c.ToColumn(cv.v.Interface())
+}
+
+// driverArgs converts values of columns with a converter and JSON columns in args into values sent
+// to database, so interceptors get ciphertexts and JSON instead of converters that hold keys.
+func driverArgs(args []any) ([]any, error) {
+	var converted []any
+	for i, arg := range args {
+		var v driver.Value
+		var err error
+		switch arg := arg.(type) {
+		case convertedValue:
+			v, err = arg.Value()
+		case jsonValue:
+			v, err = arg.Value()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert argument %d: %w", i+1, err)
+		}
+		if converted == nil {
+			converted = slices.Clone(args)
+		}
+		converted[i] = v
+	}
+	if converted == nil {
+		return args, nil
+	}
+	return converted, nil
+}
+
+// columnValues returns column and value pairs that Set writes for value of column, values of columns
+// with a converter, including encrypted ones, and JSON columns are written like values of entity
+// fields and blind index of an encrypted column is written with it.
+func (q *QueryBuilder[OUTPUT]) columnValues(column any, value any) [][2]any {
+	pairs := [][2]any{{column, value}}
+	name, isString := column.(string)
+	if q.schema == nil || !isString {
+		return pairs
+	}
+	switch value.(type) {
+	case convertedValue, jsonValue:
+		// values of entities are already converted, see genericValuesOf.
+		return pairs
+	}
+	var f *field
+	for _, sf := range q.schema.fields {
+		if sf.Name == unqualifiedColumn(name) && !sf.Virtual && !sf.IsBlindIndex {
+			f = sf
+		}
+	}
+	if f == nil {
+		return pairs
+	}
+	// NULL is written as is, for blind index column too.
+	if value != nil && f.Converter != nil {
+		pairs[0][1] = convertedValue{c: f.Converter, v: reflect.ValueOf(value)}
+	} else if value != nil && f.JSON {
+		pairs[0][1] = jsonValue{v: reflect.ValueOf(value)}
+	}
+	for _, sf := range q.schema.fields {
+		if !sf.IsBlindIndex || sf.Name != f.BlindIndex {
+			continue
+		}
+		var index any
+		if value != nil {
+			index = convertedValue{c: sf.Converter, v: reflect.ValueOf(value)}
+		}
+		pairs = append(pairs, [2]any{strings.TrimSuffix(name, f.Name) + sf.Name, index})
+	}
+	return pairs
+}
+
+// converterScanner scans a column into dst using converter c.
+type converterScanner struct {
+	c   Converter
+	dst reflect.Value
+}
+
+func (cs converterScanner) Scan(src interface{}) error {
+	v, err := cs.c.FromColumn(src)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		cs.dst.Set(reflect.Zero(cs.dst.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(cs.dst.Type()) {
+		return fmt.Errorf("converter returned %s which cannot be assigned to %s", rv.Type(), cs.dst.Type())
+	}
+	cs.dst.Set(rv)
+	return nil
+}
@@ -0,0 +1,87 @@
+package orm_test
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golobby/orm"
+	"github.com/stretchr/testify/assert"
+)
+
+type Priority int
+
+const (
+	Low Priority = iota
+	High
+)
+
+var priorityNames = map[Priority]string{Low: "low", High: "high"}
+
+var priorityConverter = orm.NewConverter(
+	func(p Priority) (driver.Value, error) {
+		name, exists := priorityNames[p]
+		if !exists {
+			return nil, fmt.Errorf("unknown priority %d", p)
+		}
+		return name, nil
+	},
+	func(src interface{}) (Priority, error) {
+		for p, name := range priorityNames {
+			if fmt.Sprintf("%s", src) == name {
+				return p, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown priority %v", src)
+	},
+)
+
+type Task struct {
+	ID       int64
+	Priority Priority
+	Timeout  time.Duration
+}
+
+func (t Task) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("tasks").Connection("default")
+	e.Field("Priority").Converter(priorityConverter)
+}
+
+func TestConverters(t *testing.T) {
+	orm.RegisterConverter[time.Duration](orm.NewConverter(
+		func(d time.Duration) (driver.Value, error) {
+			return d.Milliseconds(), nil
+		},
+		func(src interface{}) (time.Duration, error) {
+			ms, ok := src.(int64)
+			if !ok {
+				return 0, fmt.Errorf("cannot convert %T to duration", src)
+			}
+			return time.Duration(ms) * time.Millisecond, nil
+		},
+	))
+	assert.NoError(t, setup())
+	_, err := orm.GetConnection("default").DB.Exec(`CREATE TABLE tasks (id INTEGER PRIMARY KEY, priority text, timeout INTEGER)`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, orm.Insert(&Task{Priority: High, Timeout: 2 * time.Second}))
+
+	var priority string
+	var timeout int64
+	assert.NoError(t, orm.GetConnection("default").DB.QueryRow(`SELECT priority, timeout FROM tasks WHERE id = 1`).Scan(&priority, &timeout))
+	assert.Equal(t, "high", priority)
+	assert.EqualValues(t, 2000, timeout)
+
+	task, err := orm.Find[Task](1)
+	assert.NoError(t, err)
+	assert.Equal(t, Task{ID: 1, Priority: High, Timeout: 2 * time.Second}, task)
+
+	assert.Error(t, orm.Insert(&Task{Priority: Priority(7)}))
+
+	_, err = orm.Query[Task]().Where("id", 1).Set("priority", Low, "timeout", time.Second).Update()
+	assert.NoError(t, err)
+	assert.NoError(t, orm.GetConnection("default").DB.QueryRow(`SELECT priority, timeout FROM tasks WHERE id = 1`).Scan(&priority, &timeout))
+	assert.Equal(t, "low", priority)
+	assert.EqualValues(t, 1000, timeout)
+}
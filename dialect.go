@@ -3,6 +3,7 @@ package orm
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 type Dialect struct {
@@ -13,6 +14,24 @@ type Dialect struct {
 	PlaceHolderGenerator        func(n int) []string
 	QueryListTables             string
 	QueryTableSchema            string
+	// RowLocking is true when database supports row locking clauses like FOR UPDATE,
+	// locking clauses are not rendered for dialects without it.
+	RowLocking bool
+	// ShareLock is rendered for ForShare without SkipLocked or NoWait, FOR SHARE is rendered when it's empty.
+	ShareLock string
+	// JSONExtract renders value of keys inside a JSON column as an expression that can be compared
+	// with plain values, -> and ->> operators are used when it's nil.
+	JSONExtract func(column string, keys []string) string
+	// TranslateError converts driver specific errors into ORM errors like ErrUniqueViolation,
+	// errors that are not recognized should be returned as is.
+	TranslateError func(err error) error
+}
+
+func (d *Dialect) translateError(err error) error {
+	if err == nil || d == nil || d.TranslateError == nil {
+		return err
+	}
+	return d.TranslateError(err)
 }
 
 func getListOfTables(query string) func(db *sql.DB) ([]string, error) {
@@ -58,7 +77,8 @@ func getTableSchema(query string) func(db *sql.DB, query string) ([]columnSpec,
 			if err != nil {
 				return nil, err
 			}
-			cs.Nullable = nullable == "notnull"
+			// sqlite reports notnull flag as 0 or 1, mysql reports YES or NO for Null.
+			cs.Nullable = nullable == "0" || strings.EqualFold(nullable, "YES")
 			cs.IsPrimaryKey = pk == 1
 			output = append(output, cs)
 		}
@@ -80,6 +100,10 @@ var Dialects = &struct {
 		PlaceHolderGenerator:        questionMarks,
 		QueryListTables:             "SHOW TABLES",
 		QueryTableSchema:            "DESCRIBE %s",
+		RowLocking:                  true,
+		ShareLock:                   "LOCK IN SHARE MODE",
+		JSONExtract:                 mysqlJSONExtract,
+		TranslateError:              translateMySQLError,
 	},
 	PostgreSQL: &Dialect{
 		DriverName:                  "postgres",
@@ -89,6 +113,9 @@ var Dialects = &struct {
 		PlaceHolderGenerator:        postgresPlaceholder,
 		QueryListTables:             `\dt`,
 		QueryTableSchema:            `\d %s`,
+		RowLocking:                  true,
+		JSONExtract:                 jsonArrowExtract,
+		TranslateError:              translatePostgresError,
 	},
 	SQLite3: &Dialect{
 		DriverName:                  "sqlite3",
@@ -98,5 +125,8 @@ var Dialects = &struct {
 		PlaceHolderGenerator:        questionMarks,
 		QueryListTables:             "SELECT name FROM sqlite_schema WHERE type='table'",
 		QueryTableSchema:            `SELECT name,type,"notnull","dflt_value","pk" FROM PRAGMA_TABLE_INFO('%s')`,
+		RowLocking:                  false,
+		JSONExtract:                 jsonArrowExtract,
+		TranslateError:              translateSQLite3Error,
 	},
 }
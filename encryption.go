@@ -0,0 +1,302 @@
+package orm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// encryptedKeySeparator separates key ID from encrypted value in encrypted columns, like key1:base64.
+const encryptedKeySeparator = ":"
+
+// KeyProvider provides keys of encrypted fields, keys should be 16, 24 or 32 bytes to use AES-128,
+// AES-192 or AES-256. each encrypted value keeps ID of its key, so after a new key becomes current,
+// values encrypted with older ones can still be read as long as Key returns them.
+type KeyProvider interface {
+	// CurrentKey returns key and its ID that new values are encrypted with.
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns key with given ID.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider holding all keys in memory.
+type StaticKeys struct {
+	// Current is ID of key new values are encrypted with.
+	Current string
+	Keys    map[string][]byte
+}
+
+func (s StaticKeys) CurrentKey() (string, []byte, error) {
+	key, err := s.Key(s.Current)
+	return s.Current, key, err
+}
+
+func (s StaticKeys) Key(id string) ([]byte, error) {
+	key, exists := s.Keys[id]
+	if !exists {
+		return nil, fmt.Errorf("encryption key %q not found", id)
+	}
+	return key, nil
+}
+
+// encryption is how a field is encrypted, blindIndex is column name of its blind index if it has one.
+type encryption struct {
+	keys          KeyProvider
+	blindIndex    string
+	blindIndexKey []byte
+}
+
+// encryptedConverter encrypts values of a string or []byte field using AES-GCM, values are stored as
+// key ID and base64 of nonce and sealed value. if field has a converter too, it's applied before
+// encryption and after decryption.
+type encryptedConverter struct {
+	keys  KeyProvider
+	inner Converter
+	t     reflect.Type
+	// associatedData is table and column of field, it's authenticated with each value so an
+	// encrypted value copied into another column or table cannot be decrypted.
+	associatedData []byte
+}
+
+// checkEncryption returns an error for encrypted fields configured without keys, like a BlindIndex
+// without Encrypted, since their values cannot be written.
+func checkEncryption(fieldConfigurators []*FieldConfigurator) error {
+	for _, fc := range fieldConfigurators {
+		if fc.encryption == nil {
+			continue
+		}
+		keys := reflect.ValueOf(fc.encryption.keys)
+		if !keys.IsValid() || (keys.Kind() == reflect.Ptr && keys.IsNil()) {
+			return fmt.Errorf("field %s is encrypted or has a blind index but has no encryption keys, set them using Encrypted", fc.fieldName)
+		}
+		if fc.encryption.blindIndex != "" && len(fc.encryption.blindIndexKey) == 0 {
+			return fmt.Errorf("blind index %s of field %s has no key", fc.encryption.blindIndex, fc.fieldName)
+		}
+	}
+	return nil
+}
+
+// bindEncryptedColumns sets associated data of encrypted fields to their table and column, it's done
+// when schema is created since column names of nested structs are prefixed after fields are created.
+func bindEncryptedColumns(table string, fields []*field) {
+	for _, f := range fields {
+		if c, isEncrypted := f.Converter.(encryptedConverter); isEncrypted {
+			c.associatedData = []byte(table + "." + f.Name)
+			f.Converter = c
+		}
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// plaintextOf returns bytes of v that are encrypted or blind indexed, nil means NULL.
+func plaintextOf(v interface{}, inner Converter) ([]byte, error) {
+	var dv driver.Value
+	var err error
+	if inner != nil {
+		dv, err = inner.ToColumn(v)
+	} else {
+		dv, err = driver.DefaultParameterConverter.ConvertValue(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch dv := dv.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(dv), nil
+	case []byte:
+		return dv, nil
+	default:
+		return nil, fmt.Errorf("encrypted fields should have string or []byte values, got %T", dv)
+	}
+}
+
+func (c encryptedConverter) ToColumn(v interface{}) (driver.Value, error) {
+	plaintext, err := plaintextOf(v, c.inner)
+	if err != nil || plaintext == nil {
+		return nil, err
+	}
+	id, key, err := c.keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" || strings.Contains(id, encryptedKeySeparator) {
+		return nil, fmt.Errorf("encryption key ID %q should not be empty or contain %s", id, encryptedKeySeparator)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, c.associatedData)
+	return id + encryptedKeySeparator + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c encryptedConverter) FromColumn(src interface{}) (interface{}, error) {
+	var s string
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return nil, fmt.Errorf("cannot decrypt %T", src)
+	}
+	id, encoded, found := strings.Cut(s, encryptedKeySeparator)
+	if !found {
+		return nil, fmt.Errorf("encrypted value has no key ID")
+	}
+	key, err := c.keys.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode encrypted value: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted value is too short")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], c.associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt value with key %q: %w", id, err)
+	}
+	if c.inner != nil {
+		return c.inner.FromColumn(plaintext)
+	}
+	return valueFromPlaintext(c.t, plaintext)
+}
+
+// valueFromPlaintext creates a value of type t from decrypted bytes, t can be a string or []byte,
+// a pointer to them or a sql.Scanner like Null[string].
+func valueFromPlaintext(t reflect.Type, plaintext []byte) (interface{}, error) {
+	v := reflect.New(t)
+	if scanner, isScanner := v.Interface().(sql.Scanner); isScanner {
+		if err := scanner.Scan(plaintext); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	}
+	switch {
+	case t.Kind() == reflect.String:
+		v.Elem().SetString(string(plaintext))
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		v.Elem().SetBytes(plaintext)
+	case t.Kind() == reflect.Ptr:
+		elem, err := valueFromPlaintext(t.Elem(), plaintext)
+		if err != nil {
+			return nil, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(reflect.ValueOf(elem))
+		return ptr.Interface(), nil
+	default:
+		return nil, fmt.Errorf("encrypted fields should be string or []byte, got %s", t)
+	}
+	return v.Elem().Interface(), nil
+}
+
+// blindIndexConverter writes HMAC-SHA256 of plaintext of encrypted field into its blind index column,
+// it's deterministic so equal values have equal blind indexes and can be looked up using Where.
+type blindIndexConverter struct {
+	key   []byte
+	inner Converter
+}
+
+func (c blindIndexConverter) ToColumn(v interface{}) (driver.Value, error) {
+	plaintext, err := plaintextOf(v, c.inner)
+	if err != nil || plaintext == nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(plaintext)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// FromColumn is never used since blind index columns are not bound to fields.
+func (c blindIndexConverter) FromColumn(src interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+// useBlindIndex rewrites condition on an encrypted column to compare its blind index instead, since
+// encrypted values are different each time they are written. it returns an error for encrypted
+// columns without blind index or operators that blind index cannot be used with.
+func (q *QueryBuilder[OUTPUT]) useBlindIndex(w *whereClause) error {
+	if q.schema == nil || w.raw != "" || w.Lhs == "" {
+		return nil
+	}
+	column := unqualifiedColumn(w.Lhs)
+	if table := strings.TrimSuffix(w.Lhs, "."+column); table != w.Lhs && table != q.schema.Table && table != q.alias {
+		// column of another table.
+		return nil
+	}
+	var encrypted, blindIndex *field
+	for _, f := range q.schema.fields {
+		if f.Name == column && f.Encrypted {
+			encrypted = f
+		}
+	}
+	if encrypted == nil {
+		return nil
+	}
+	for _, f := range q.schema.fields {
+		if f.IsBlindIndex && f.Name == encrypted.BlindIndex {
+			blindIndex = f
+		}
+	}
+	if blindIndex == nil {
+		return fmt.Errorf("column %s is encrypted and has no blind index to look it up", column)
+	}
+	switch w.Op {
+	case Eq, NE:
+		v, err := blindIndex.Converter.ToColumn(w.Rhs)
+		if err != nil {
+			return err
+		}
+		w.Rhs = v
+	case In:
+		values, isList := w.Rhs.([]interface{})
+		if !isList {
+			return fmt.Errorf("blind index of %s can only be looked up with a list of values", column)
+		}
+		indexes := make([]interface{}, len(values))
+		for i, value := range values {
+			v, err := blindIndex.Converter.ToColumn(value)
+			if err != nil {
+				return err
+			}
+			indexes[i] = v
+		}
+		w.Rhs = indexes
+	default:
+		return fmt.Errorf("encrypted column %s can only be compared using =, != or IN", column)
+	}
+	w.Lhs = strings.TrimSuffix(w.Lhs, column) + blindIndex.Name
+	return nil
+}
@@ -0,0 +1,165 @@
+package orm_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/golobby/orm"
+	"github.com/stretchr/testify/assert"
+)
+
+var customerKeys = &orm.StaticKeys{
+	Current: "k1",
+	Keys: map[string][]byte{
+		"k1": []byte("0123456789abcdef0123456789abcdef"),
+	},
+}
+
+type Customer struct {
+	ID    int64
+	Email string
+	Phone orm.Null[string]
+}
+
+func (c Customer) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("customers").Connection("default")
+	e.Field("Email").Encrypted(customerKeys).BlindIndex("email_bidx", []byte("blind index key"))
+	e.Field("Phone").Encrypted(customerKeys)
+}
+
+type Person struct {
+	ID    int64
+	Email string
+	Phone string
+}
+
+func (p Person) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("people").Connection("default")
+	e.Field("Email").BlindIndex("email_idx", []byte("blind index key"))
+}
+
+type Patient struct {
+	ID    int64
+	Email string
+}
+
+func (p Patient) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("patients").Connection("default")
+	e.Field("Email").Encrypted(nil)
+}
+
+func TestEncryptionWithoutKeys(t *testing.T) {
+	assert.NoError(t, setup())
+	assert.ErrorContains(t, orm.Insert(&Person{Email: "a@example.com"}), "no encryption keys")
+	assert.ErrorContains(t, orm.Insert(&Patient{Email: "a@example.com"}), "no encryption keys")
+}
+
+func TestEncryption(t *testing.T) {
+	assert.NoError(t, setup())
+	_, err := orm.GetConnection("default").DB.Exec(`CREATE TABLE customers (id INTEGER PRIMARY KEY, email text, email_bidx text, phone text)`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, orm.Insert(&Customer{Email: "a@example.com", Phone: orm.NewNull("0912")}))
+	assert.NoError(t, orm.Insert(&Customer{Email: "b@example.com"}))
+
+	var email, phone string
+	assert.NoError(t, orm.GetConnection("default").DB.QueryRow(`SELECT email, phone FROM customers WHERE id = 1`).Scan(&email, &phone))
+	assert.True(t, strings.HasPrefix(email, "k1:"))
+	assert.NotContains(t, email, "a@example.com")
+	assert.True(t, strings.HasPrefix(phone, "k1:"))
+
+	c, err := orm.Find[Customer](1)
+	assert.NoError(t, err)
+	assert.Equal(t, Customer{ID: 1, Email: "a@example.com", Phone: orm.NewNull("0912")}, c)
+
+	t.Run("lookup by blind index", func(t *testing.T) {
+		c, err := orm.Query[Customer]().Where("email", "b@example.com").Get()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, c.ID)
+		assert.False(t, c.Phone.Valid)
+
+		cs, err := orm.Query[Customer]().WhereIn("customers.email", "a@example.com", "b@example.com").All()
+		assert.NoError(t, err)
+		assert.Len(t, cs, 2)
+	})
+	t.Run("encrypted column without blind index", func(t *testing.T) {
+		_, err := orm.Query[Customer]().Where("phone", "0912").All()
+		assert.Error(t, err)
+		_, err = orm.Query[Customer]().Where("email", orm.Like, "a%").All()
+		assert.Error(t, err)
+	})
+	t.Run("set encrypts value and its blind index", func(t *testing.T) {
+		_, err := orm.Query[Customer]().Where("id", 2).Set("email", "d@example.com", "phone", "0935").Update()
+		assert.NoError(t, err)
+
+		var email, phone string
+		assert.NoError(t, orm.GetConnection("default").DB.QueryRow(`SELECT email, phone FROM customers WHERE id = 2`).Scan(&email, &phone))
+		assert.True(t, strings.HasPrefix(email, "k1:"))
+		assert.True(t, strings.HasPrefix(phone, "k1:"))
+
+		c, err := orm.Query[Customer]().Where("email", "d@example.com").Get()
+		assert.NoError(t, err)
+		assert.Equal(t, Customer{ID: 2, Email: "d@example.com", Phone: orm.NewNull("0935")}, c)
+
+		_, err = orm.Query[Customer]().Where("id", 2).Set("email", "b@example.com", "phone", nil).Update()
+		assert.NoError(t, err)
+		c, err = orm.Find[Customer](2)
+		assert.NoError(t, err)
+		assert.Equal(t, Customer{ID: 2, Email: "b@example.com"}, c)
+	})
+	t.Run("encrypted values are bound to their column", func(t *testing.T) {
+		_, err := orm.GetConnection("default").DB.Exec(`UPDATE customers SET phone = email WHERE id = 2`)
+		assert.NoError(t, err)
+		_, err = orm.Find[Customer](2)
+		assert.Error(t, err)
+		_, err = orm.Query[Customer]().Where("id", 2).Set("phone", nil).Update()
+		assert.NoError(t, err)
+	})
+	t.Run("key rotation", func(t *testing.T) {
+		customerKeys.Keys["k2"] = []byte("fedcba9876543210")
+		customerKeys.Current = "k2"
+		defer func() {
+			customerKeys.Current = "k1"
+			delete(customerKeys.Keys, "k2")
+		}()
+
+		c, err := orm.Find[Customer](1)
+		assert.NoError(t, err)
+		c.Email = "c@example.com"
+		assert.NoError(t, orm.Update(&c))
+
+		var email string
+		assert.NoError(t, orm.GetConnection("default").DB.QueryRow(`SELECT email FROM customers WHERE id = 1`).Scan(&email))
+		assert.True(t, strings.HasPrefix(email, "k2:"))
+
+		c, err = orm.Query[Customer]().Where("email", "c@example.com").Get()
+		assert.NoError(t, err)
+		assert.Equal(t, "c@example.com", c.Email)
+		// values encrypted with k1 are still readable.
+		assert.Equal(t, orm.NewNull("0912"), c.Phone)
+	})
+}
+
+func TestEncryptionLogs(t *testing.T) {
+	assert.NoError(t, setup())
+	_, err := orm.GetConnection("default").DB.Exec(`CREATE TABLE customers (id INTEGER PRIMARY KEY, email text, email_bidx text, phone text)`)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	orm.GetConnection("default").AddInterceptor(orm.SlogInterceptor{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+	assert.NoError(t, orm.Insert(&Customer{Email: "a@example.com", Phone: orm.NewNull("0912")}))
+	_, err = orm.Query[Customer]().Where("id", 1).Set("email", "b@example.com").Update()
+	assert.NoError(t, err)
+	_, err = orm.Query[Customer]().Where("email", "b@example.com").Get()
+	assert.NoError(t, err)
+
+	logs := buf.String()
+	assert.Contains(t, logs, "k1:")
+	for _, secret := range []string{"0123456789abcdef", "blind index key", "a@example.com", "b@example.com", "0912"} {
+		assert.NotContains(t, logs, secret)
+		assert.NotContains(t, logs, strings.Trim(fmt.Sprint([]byte(secret)), "[]"))
+	}
+}
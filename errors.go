@@ -0,0 +1,113 @@
+package orm
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	// ErrNotFound is returned by finishers like Find and Get when
+	// query has no matching row.
+	ErrNotFound = errors.New("record not found")
+	// ErrNoConnection is returned when there is no connection registered
+	// that an entity can use.
+	ErrNoConnection = errors.New("no db found")
+	// ErrRelationNotConfigured is returned when a relation is queried or used
+	// but it's not defined in ConfigureEntity of the entity.
+	ErrRelationNotConfigured = errors.New("relation is not configured")
+	// ErrUniqueViolation is returned when database rejects a query because of a
+	// unique or primary key constraint.
+	ErrUniqueViolation = errors.New("unique constraint violation")
+	// ErrForeignKeyViolation is returned when database rejects a query because of
+	// a foreign key constraint.
+	ErrForeignKeyViolation = errors.New("foreign key constraint violation")
+	// ErrNotNullViolation is returned when database rejects a query because
+	// a NOT NULL column is getting a NULL value.
+	ErrNotNullViolation = errors.New("not null constraint violation")
+	// ErrInvalidCursor is returned by CursorPaginate when given cursor is malformed or
+	// does not match order columns.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// ConstraintError wraps an error returned from database driver and classifies it
+// as one of ErrUniqueViolation, ErrForeignKeyViolation or ErrNotNullViolation,
+// so you can match it using errors.Is and still reach the driver error using errors.As.
+type ConstraintError struct {
+	// Kind is one of the constraint sentinel errors.
+	Kind error
+	// Err is the original driver error.
+	Err error
+}
+
+func (c *ConstraintError) Error() string {
+	return c.Kind.Error() + ": " + c.Err.Error()
+}
+
+func (c *ConstraintError) Unwrap() error {
+	return c.Err
+}
+
+func (c *ConstraintError) Is(target error) bool {
+	return c.Kind == target
+}
+
+func constraintError(kind error, err error) error {
+	if kind == nil {
+		return err
+	}
+	return &ConstraintError{Kind: kind, Err: err}
+}
+
+func translateMySQLError(err error) error {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return err
+	}
+	var kind error
+	switch myErr.Number {
+	case 1062, 1586:
+		kind = ErrUniqueViolation
+	case 1451, 1452, 1216, 1217:
+		kind = ErrForeignKeyViolation
+	case 1048, 1364:
+		kind = ErrNotNullViolation
+	}
+	return constraintError(kind, err)
+}
+
+func translatePostgresError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	var kind error
+	switch pqErr.Code {
+	case "23505":
+		kind = ErrUniqueViolation
+	case "23503":
+		kind = ErrForeignKeyViolation
+	case "23502":
+		kind = ErrNotNullViolation
+	}
+	return constraintError(kind, err)
+}
+
+func translateSQLite3Error(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	var kind error
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		kind = ErrUniqueViolation
+	case sqlite3.ErrConstraintForeignKey:
+		kind = ErrForeignKeyViolation
+	case sqlite3.ErrConstraintNotNull:
+		kind = ErrNotNullViolation
+	}
+	return constraintError(kind, err)
+}
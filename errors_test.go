@@ -0,0 +1,44 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateError(t *testing.T) {
+	t.Run("mysql", func(t *testing.T) {
+		assert.ErrorIs(t, Dialects.MySQL.translateError(&mysql.MySQLError{Number: 1062}), ErrUniqueViolation)
+		assert.ErrorIs(t, Dialects.MySQL.translateError(&mysql.MySQLError{Number: 1452}), ErrForeignKeyViolation)
+		assert.ErrorIs(t, Dialects.MySQL.translateError(&mysql.MySQLError{Number: 1048}), ErrNotNullViolation)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		assert.ErrorIs(t, Dialects.PostgreSQL.translateError(&pq.Error{Code: "23505"}), ErrUniqueViolation)
+		assert.ErrorIs(t, Dialects.PostgreSQL.translateError(&pq.Error{Code: "23503"}), ErrForeignKeyViolation)
+		assert.ErrorIs(t, Dialects.PostgreSQL.translateError(&pq.Error{Code: "23502"}), ErrNotNullViolation)
+	})
+	t.Run("sqlite3", func(t *testing.T) {
+		assert.ErrorIs(t, Dialects.SQLite3.translateError(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}), ErrUniqueViolation)
+		assert.ErrorIs(t, Dialects.SQLite3.translateError(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintPrimaryKey}), ErrUniqueViolation)
+		assert.ErrorIs(t, Dialects.SQLite3.translateError(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey}), ErrForeignKeyViolation)
+		assert.ErrorIs(t, Dialects.SQLite3.translateError(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull}), ErrNotNullViolation)
+	})
+	t.Run("driver error is still reachable", func(t *testing.T) {
+		err := Dialects.PostgreSQL.translateError(fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}))
+		var pqErr *pq.Error
+		assert.True(t, errors.As(err, &pqErr))
+		assert.EqualValues(t, "23505", pqErr.Code)
+	})
+	t.Run("unknown errors are returned as is", func(t *testing.T) {
+		err := errors.New("connection refused")
+		assert.Equal(t, err, Dialects.MySQL.translateError(err))
+		pqErr := &pq.Error{Code: "42P01"}
+		assert.Equal(t, pqErr, Dialects.PostgreSQL.translateError(pqErr))
+		assert.Nil(t, Dialects.SQLite3.translateError(nil))
+	})
+}
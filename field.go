@@ -2,9 +2,12 @@ package orm
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"github.com/iancoleman/strcase"
 	"reflect"
+	"slices"
 	"strings"
+	"unsafe"
 )
 
 type field struct {
@@ -15,8 +18,24 @@ type field struct {
 	IsUpdatedAt bool
 	IsDeletedAt bool
 	Nullable    bool
-	Default     any
-	Type        reflect.Type
+	// Index is set by index tag option, it records that column is looked up often and should be indexed.
+	Index bool
+	// JSON fields are marshaled into a single JSON column instead of being flattened.
+	JSON bool
+	// Converter converts values of field when they are written and read, it's nil for fields
+	// that use their own type as column value.
+	Converter Converter
+	// Encrypted fields are stored encrypted, BlindIndex is column name of their blind index if they have one.
+	Encrypted  bool
+	BlindIndex string
+	// IsBlindIndex fields are blind index columns of an encrypted field, they share its Path
+	// but are only written.
+	IsBlindIndex bool
+	Default      any
+	Type         reflect.Type
+	// Path is index sequence of field in entity struct, like reflect.Value.FieldByIndex,
+	// so fields of nested and embedded structs are reached directly.
+	Path []int
 }
 
 func getFieldConfiguratorFor(fieldConfigurators []*FieldConfigurator, name string) *FieldConfigurator {
@@ -28,40 +47,219 @@ func getFieldConfiguratorFor(fieldConfigurators []*FieldConfigurator, name strin
 	return &FieldConfigurator{}
 }
 
+// fieldTag is the parsed orm struct tag of a field, like `orm:"column:user_name;pk;nullable"`.
+type fieldTag struct {
+	column    string
+	pk        bool
+	createdAt bool
+	updatedAt bool
+	deletedAt bool
+	ignore    bool
+	nullable  bool
+	index     bool
+	json      bool
+	prefix    string
+}
+
+// parseFieldTag parses options of an orm struct tag, options are separated by ; and
+// their names are case insensitive.
+func parseFieldTag(tag string) (fieldTag, error) {
+	var ft fieldTag
+	for _, option := range strings.Split(tag, ";") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		key, value, hasValue := strings.Cut(option, ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if hasValue && key != "column" && key != "prefix" {
+			return ft, fmt.Errorf("orm tag option %s does not take a value", key)
+		}
+		switch key {
+		case "column":
+			ft.column = strings.TrimSpace(value)
+			if ft.column == "" {
+				return ft, fmt.Errorf("orm tag option column needs a name")
+			}
+		case "prefix":
+			ft.prefix = strings.TrimSpace(value)
+			if ft.prefix == "" {
+				return ft, fmt.Errorf("orm tag option prefix needs a value")
+			}
+		case "pk":
+			ft.pk = true
+		case "createdat":
+			ft.createdAt = true
+		case "updatedat":
+			ft.updatedAt = true
+		case "deletedat":
+			ft.deletedAt = true
+		case "ignore":
+			ft.ignore = true
+		case "nullable":
+			ft.nullable = true
+		case "index":
+			ft.index = true
+		case "json":
+			ft.json = true
+		default:
+			return ft, fmt.Errorf("unknown orm tag option %q", option)
+		}
+	}
+	return ft, nil
+}
+
+// checkFieldTags returns an error for the first invalid orm tag in fields of t and its nested structs,
+// ignored fields are not columns so their types are not checked.
+func checkFieldTags(t reflect.Type) error {
+	return checkFieldTagsOf(t, map[reflect.Type]bool{})
+}
+
+// checkFieldTagsOf is checkFieldTags that skips types in visited, so self referencing types are checked once.
+func checkFieldTagsOf(t reflect.Type, visited map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem()) || visited[t] {
+		return nil
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, err := parseFieldTag(sf.Tag.Get("orm"))
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %w", sf.Name, t.Name(), err)
+		}
+		if tag.ignore {
+			continue
+		}
+		if err := checkFieldTagsOf(sf.Type, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldMetadata creates metadata of a struct field, settings from ConfigureEntity take precedence
+// over orm struct tag of field and tag takes precedence over naming conventions.
 func fieldMetadata(ft reflect.StructField, fieldConfigurators []*FieldConfigurator) []*field {
 	var fms []*field
 	fc := getFieldConfiguratorFor(fieldConfigurators, ft.Name)
+	// invalid tags are reported when schema is created, see checkFieldTags.
+	tag, _ := parseFieldTag(ft.Tag.Get("orm"))
 	baseFm := &field{}
 	baseFm.Type = ft.Type
+	baseFm.Path = slices.Clone(ft.Index)
 	fms = append(fms, baseFm)
 	if fc.column != "" {
 		baseFm.Name = fc.column
+	} else if tag.column != "" {
+		baseFm.Name = tag.column
 	} else {
 		baseFm.Name = strcase.ToSnake(ft.Name)
 	}
-	if strings.ToLower(ft.Name) == "id" || fc.primaryKey {
+	if strings.ToLower(ft.Name) == "id" || fc.primaryKey || tag.pk {
 		baseFm.IsPK = true
 	}
-	if strings.ToLower(ft.Name) == "createdat" || fc.isCreatedAt {
+	if strings.ToLower(ft.Name) == "createdat" || fc.isCreatedAt || tag.createdAt {
 		baseFm.IsCreatedAt = true
 	}
-	if strings.ToLower(ft.Name) == "updatedat" || fc.isUpdatedAt {
+	if strings.ToLower(ft.Name) == "updatedat" || fc.isUpdatedAt || tag.updatedAt {
 		baseFm.IsUpdatedAt = true
 	}
-	if strings.ToLower(ft.Name) == "deletedat" || fc.isDeletedAt {
+	if strings.ToLower(ft.Name) == "deletedat" || fc.isDeletedAt || tag.deletedAt {
 		baseFm.IsDeletedAt = true
 	}
-	if ft.Type.Kind() == reflect.Struct || ft.Type.Kind() == reflect.Ptr {
+	if fc.nullable.Valid {
+		baseFm.Nullable = fc.nullable.Bool
+	} else {
+		baseFm.Nullable = tag.nullable || isNullType(ft.Type)
+	}
+	baseFm.Index = tag.index
+	baseFm.Virtual = fc.virtual || tag.ignore
+	baseFm.JSON = fc.json || tag.json
+	if fc.converter != nil {
+		baseFm.Converter = fc.converter
+	} else {
+		baseFm.Converter = converterOf(ft.Type)
+	}
+	if fc.encryption != nil {
+		baseFm.Encrypted = true
+		inner := baseFm.Converter
+		baseFm.Converter = encryptedConverter{keys: fc.encryption.keys, inner: inner, t: ft.Type}
+		if fc.encryption.blindIndex != "" {
+			baseFm.BlindIndex = fc.encryption.blindIndex
+			fms = append(fms, &field{
+				Name:         fc.encryption.blindIndex,
+				Type:         ft.Type,
+				Path:         slices.Clone(baseFm.Path),
+				Converter:    blindIndexConverter{key: fc.encryption.blindIndexKey, inner: inner},
+				IsBlindIndex: true,
+				Virtual:      baseFm.Virtual,
+				Nullable:     baseFm.Nullable,
+			})
+		}
+	}
+	// virtual fields are not columns, so their structs are not flattened, they may even reference the entity itself.
+	if isNestedStruct(ft.Type) && !baseFm.JSON && baseFm.Converter == nil && !baseFm.Virtual {
 		t := ft.Type
-		if ft.Type.Kind() == reflect.Ptr {
-			t = ft.Type.Elem()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
 		}
-		if !t.Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem()) {
-			for i := 0; i < t.NumField(); i++ {
-				fms = append(fms, fieldMetadata(t.Field(i), fieldConfigurators)...)
+		prefix := tag.prefix
+		if fc.prefix != "" {
+			prefix = fc.prefix
+		}
+		for i := 0; i < t.NumField(); i++ {
+			for _, fm := range fieldMetadata(t.Field(i), fieldConfigurators) {
+				fm.Name = prefix + fm.Name
+				if fm.BlindIndex != "" {
+					fm.BlindIndex = prefix + fm.BlindIndex
+				}
+				fm.Path = append(slices.Clone(baseFm.Path), fm.Path...)
+				fms = append(fms, fm)
 			}
-			fms = fms[1:]
 		}
+		fms = fms[1:]
+	}
+	return fms
+}
+
+// fieldsOfType returns metadata of all fields of struct type t, nested structs are flattened.
+func fieldsOfType(t reflect.Type, fieldConfigurators []*FieldConfigurator) []*field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var fms []*field
+	for i := 0; i < t.NumField(); i++ {
+		fms = append(fms, fieldMetadata(t.Field(i), fieldConfigurators)...)
 	}
 	return fms
 }
+
+// fieldByPath returns field of struct v at path. nil pointers on the way are allocated when alloc
+// is true and v is addressable, otherwise false is returned. Returned field is settable if v is
+// addressable, even when it's unexported.
+func fieldByPath(v reflect.Value, path []int, alloc bool) (reflect.Value, bool) {
+	for _, i := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanAddr() {
+					return reflect.Value{}, false
+				}
+				v = settable(v)
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return settable(v), true
+}
+
+func settable(v reflect.Value) reflect.Value {
+	if v.CanSet() || !v.CanAddr() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,140 @@
+package orm
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// QueryEvent contains information about a query that ORM runs on a connection.
+type QueryEvent struct {
+	// Connection is name of the connection query is running on.
+	Connection string
+	// Driver is the driver name of connection dialect, for example sqlite3.
+	Driver string
+	// Table is the table of entity that query is built for, it's empty for raw queries.
+	Table string
+	// Operation is the SQL command of the query like SELECT, INSERT, UPDATE or DELETE.
+	Operation string
+	// Query is the SQL string sent to database.
+	Query string
+	// Args are arguments of the query, values of columns with a converter, including encrypted
+	// ones, and JSON columns are already converted into what is sent to database. it's a copy
+	// so changing it does not change what is sent to database.
+	Args []interface{}
+	// Duration is how long database took to run the query, it's
+	// set before AfterQuery is called.
+	Duration time.Duration
+	// RowsAffected is number of rows affected by an Exec query, it's -1 for
+	// queries that return rows or when driver does not report it.
+	RowsAffected int64
+	// Err is the error returned from database if there is any.
+	Err error
+}
+
+// Interceptor receives every query that ORM runs on a connection, including raw queries.
+// BeforeQuery is called before query is sent to database and the context it returns
+// is used for running the query and is passed to AfterQuery.
+type Interceptor interface {
+	BeforeQuery(ctx context.Context, event *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// InterceptorFunc is an Interceptor that only cares about finished queries.
+type InterceptorFunc func(ctx context.Context, event *QueryEvent)
+
+func (f InterceptorFunc) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (f InterceptorFunc) AfterQuery(ctx context.Context, event *QueryEvent) {
+	f(ctx, event)
+}
+
+// SlogInterceptor logs every query using a slog.Logger.
+type SlogInterceptor struct {
+	// Logger to write logs into, slog.Default() is used if it's nil.
+	Logger *slog.Logger
+	// Level of logs for successful queries, failed queries are always logged in slog.LevelError.
+	Level slog.Level
+	// SlowThreshold if set, queries that take longer than it are logged in slog.LevelWarn.
+	SlowThreshold time.Duration
+	// Redact if set, is called with query arguments before logging them so you can
+	// hide sensitive values.
+	Redact func(query string, args []interface{}) []interface{}
+}
+
+func (s SlogInterceptor) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (s SlogInterceptor) AfterQuery(ctx context.Context, event *QueryEvent) {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	args := event.Args
+	if s.Redact != nil {
+		args = s.Redact(event.Query, args)
+	}
+	attrs := []slog.Attr{
+		slog.String("connection", event.Connection),
+		slog.String("table", event.Table),
+		slog.String("operation", event.Operation),
+		slog.String("query", event.Query),
+		slog.Any("args", args),
+		slog.Duration("duration", event.Duration),
+		slog.Int64("rows_affected", event.RowsAffected),
+	}
+	level := s.Level
+	msg := "query"
+	if event.Err != nil {
+		level = slog.LevelError
+		msg = "query failed"
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	} else if s.SlowThreshold > 0 && event.Duration > s.SlowThreshold {
+		level = slog.LevelWarn
+		msg = "slow query"
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (c *connection) beforeQuery(ctx context.Context, event *QueryEvent) context.Context {
+	for _, i := range c.interceptors {
+		ctx = i.BeforeQuery(ctx, event)
+	}
+	return ctx
+}
+
+func (c *connection) afterQuery(ctx context.Context, event *QueryEvent) {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		c.interceptors[i].AfterQuery(ctx, event)
+	}
+}
+
+func (c *connection) newQueryEvent(table string, q string, args []interface{}) *QueryEvent {
+	argsCopy := make([]interface{}, len(args))
+	copy(argsCopy, args)
+	event := &QueryEvent{
+		Connection:   c.Name,
+		Table:        table,
+		Operation:    queryOperation(q),
+		Query:        q,
+		Args:         argsCopy,
+		RowsAffected: -1,
+	}
+	if c.Dialect != nil {
+		event.Driver = c.Dialect.DriverName
+	}
+	return event
+}
+
+// queryOperation returns the first keyword of given query in upper case.
+func queryOperation(q string) string {
+	fields := strings.Fields(q)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
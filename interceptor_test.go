@@ -0,0 +1,100 @@
+package orm_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golobby/orm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterceptors(t *testing.T) {
+	t.Run("interceptor receives queries", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		var events []orm.QueryEvent
+		orm.GetConnection("default").AddInterceptor(orm.InterceptorFunc(func(ctx context.Context, event *orm.QueryEvent) {
+			events = append(events, *event)
+		}))
+
+		post := &Post{BodyText: "body 1"}
+		assert.NoError(t, orm.Insert(post))
+		assert.NoError(t, orm.Add(post, &Comment{Body: "comment 1"}))
+		_, err = orm.QueryRaw[Post](`SELECT * FROM posts WHERE id = ?`, post.ID)
+		assert.NoError(t, err)
+		_, _, err = orm.ExecRaw[Post](`INSERT INTO posts (id, body) VALUES (?, ?)`, post.ID, "duplicate")
+		assert.ErrorIs(t, err, orm.ErrUniqueViolation)
+
+		assert.Len(t, events, 4)
+		assert.Equal(t, "default", events[0].Connection)
+		assert.Contains(t, events[0].Query, "INSERT INTO posts")
+		assert.EqualValues(t, 1, events[0].RowsAffected)
+		assert.Contains(t, events[1].Query, "INSERT INTO comments")
+		assert.Equal(t, []interface{}{post.ID}, events[2].Args)
+		assert.EqualValues(t, -1, events[2].RowsAffected)
+		assert.ErrorIs(t, events[3].Err, orm.ErrUniqueViolation)
+	})
+
+	t.Run("interceptors are called in order", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		var calls []string
+		orm.GetConnection("default").AddInterceptor(recordingInterceptor{"first", &calls}, recordingInterceptor{"second", &calls})
+
+		_, err = orm.Query[Post]().All()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"before first", "before second", "after second", "after first"}, calls)
+	})
+
+	t.Run("slog interceptor", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		orm.GetConnection("default").AddInterceptor(orm.SlogInterceptor{
+			Logger: slog.New(slog.NewTextHandler(&buf, nil)),
+			Redact: func(query string, args []interface{}) []interface{} {
+				redacted := make([]interface{}, len(args))
+				for i := range args {
+					redacted[i] = "***"
+				}
+				return redacted
+			},
+		})
+
+		assert.NoError(t, orm.Insert(&Post{BodyText: "secret body"}))
+		assert.Contains(t, buf.String(), "INSERT INTO posts")
+		assert.Contains(t, buf.String(), "***")
+		assert.NotContains(t, buf.String(), "secret body")
+	})
+
+	t.Run("slog interceptor slow queries", func(t *testing.T) {
+		var buf bytes.Buffer
+		orm.SlogInterceptor{
+			Logger:        slog.New(slog.NewTextHandler(&buf, nil)),
+			SlowThreshold: time.Millisecond,
+		}.AfterQuery(context.Background(), &orm.QueryEvent{Query: "SELECT 1", Duration: time.Second})
+
+		assert.Contains(t, buf.String(), "level=WARN")
+		assert.Contains(t, buf.String(), "slow query")
+	})
+}
+
+type recordingInterceptor struct {
+	name  string
+	calls *[]string
+}
+
+func (r recordingInterceptor) BeforeQuery(ctx context.Context, event *orm.QueryEvent) context.Context {
+	*r.calls = append(*r.calls, "before "+r.name)
+	return ctx
+}
+
+func (r recordingInterceptor) AfterQuery(ctx context.Context, event *orm.QueryEvent) {
+	*r.calls = append(*r.calls, "after "+r.name)
+}
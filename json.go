@@ -0,0 +1,128 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// jsonPathSeparator separates column from keys in paths passed to WhereJSON, like meta->plan.
+const jsonPathSeparator = "->"
+
+// jsonValue is written to a JSON column as marshaled JSON of v, nil maps, slices and pointers are written as NULL.
+type jsonValue struct {
+	v reflect.Value
+}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	switch j.v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
+		if j.v.IsNil() {
+			return nil, nil
+		}
+	}
+	bs, err := json.Marshal(j.v.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal %s into JSON column: %w", j.v.Type(), err)
+	}
+	return string(bs), nil
+}
+
+// jsonScanner scans a JSON column into dst by unmarshaling it, NULL sets dst to its zero value.
+type jsonScanner struct {
+	dst reflect.Value
+}
+
+func (j jsonScanner) Scan(src interface{}) error {
+	var bs []byte
+	switch src := src.(type) {
+	case nil:
+		j.dst.Set(reflect.Zero(j.dst.Type()))
+		return nil
+	case []byte:
+		bs = src
+	case string:
+		bs = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T from JSON column into %s", src, j.dst.Type())
+	}
+	v := reflect.New(j.dst.Type())
+	if err := json.Unmarshal(bs, v.Interface()); err != nil {
+		return fmt.Errorf("cannot unmarshal JSON column into %s: %w", j.dst.Type(), err)
+	}
+	j.dst.Set(v.Elem())
+	return nil
+}
+
+// parseJSONPath splits a path like meta->plan->name into its column and keys.
+func parseJSONPath(path string) (string, []string, error) {
+	parts := strings.Split(path, jsonPathSeparator)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+		if parts[i] == "" {
+			return "", nil, fmt.Errorf("invalid JSON path %q", path)
+		}
+	}
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("JSON path %q should have a column and at least one key like column->key", path)
+	}
+	return parts[0], parts[1:], nil
+}
+
+func quoteJSONKey(key string) string {
+	return "'" + strings.ReplaceAll(key, "'", "''") + "'"
+}
+
+// jsonArrowExtract renders value of keys in column as text using -> and ->> operators, like meta->'a'->>'b'.
+func jsonArrowExtract(column string, keys []string) string {
+	for _, key := range keys[:len(keys)-1] {
+		column += "->" + quoteJSONKey(key)
+	}
+	return column + "->>" + quoteJSONKey(keys[len(keys)-1])
+}
+
+func jsonDollarPath(keys []string) string {
+	var path strings.Builder
+	path.WriteString("$")
+	for _, key := range keys {
+		path.WriteString(`."` + strings.ReplaceAll(key, `"`, `\"`) + `"`)
+	}
+	return quoteJSONKey(path.String())
+}
+
+// mysqlJSONExtract renders value of keys in column unquoted, like JSON_UNQUOTE(JSON_EXTRACT(meta, '$."plan"')).
+func mysqlJSONExtract(column string, keys []string) string {
+	return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, %s))", column, jsonDollarPath(keys))
+}
+
+// WhereJSON adds a where clause on a key inside a JSON column, path is column name and keys separated
+// by ->, like meta->plan or meta->limits->seats, rest of arguments are same as Where.
+func (q *QueryBuilder[OUTPUT]) WhereJSON(path string, parts ...interface{}) *QueryBuilder[OUTPUT] {
+	return q.addJSONWhere(nextType_AND, path, parts...)
+}
+
+// OrWhereJSON is like WhereJSON but joins the clause with OR.
+func (q *QueryBuilder[OUTPUT]) OrWhereJSON(path string, parts ...interface{}) *QueryBuilder[OUTPUT] {
+	return q.addJSONWhere(nextType_OR, path, parts...)
+}
+
+func (q *QueryBuilder[OUTPUT]) addJSONWhere(typ string, path string, parts ...interface{}) *QueryBuilder[OUTPUT] {
+	if q.err != nil {
+		return q
+	}
+	column, keys, err := parseJSONPath(path)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	w, err := newWhereClause(append([]interface{}{column}, parts...)...)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	w.jsonKeys = keys
+	q.where = appendWhereClause(q.where, typ, w)
+	return q
+}
@@ -0,0 +1,40 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Metrics collects measurements of queries that run on a connection, set it in
+// ConnectionConfig.Metrics. ormprom package contains a Prometheus implementation.
+type Metrics interface {
+	// ObserveQuery is called after each query with connection name, table of the entity, operation
+	// (select, insert, update, delete) and how long it took, err is the error query returned.
+	ObserveQuery(connection string, table string, operation string, duration time.Duration, err error)
+}
+
+type metricsInterceptor struct {
+	metrics Metrics
+}
+
+func (m metricsInterceptor) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (m metricsInterceptor) AfterQuery(_ context.Context, event *QueryEvent) {
+	m.metrics.ObserveQuery(event.Connection, event.Table, strings.ToLower(event.Operation), event.Duration, event.Err)
+}
+
+// DBStats returns connection pool statistics of all connections by their name.
+func DBStats() map[string]sql.DBStats {
+	stats := map[string]sql.DBStats{}
+	for name, conn := range globalConnections {
+		if conn.DB == nil {
+			continue
+		}
+		stats[name] = conn.DB.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,81 @@
+package orm
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+)
+
+// Null represents a value of T that may be NULL, it can be used for any column type instead of
+// sql.NullString, sql.NullInt64 and others. Fields of type Null are nullable columns.
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NewNull returns a valid Null holding v.
+func NewNull[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true}
+}
+
+// Ptr returns a pointer to value of n or nil when n is NULL.
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	v := n.V
+	return &v
+}
+
+// Scan implements sql.Scanner, it accepts same source values as scanning into T.
+func (n *Null[T]) Scan(src interface{}) error {
+	var sn sql.Null[T]
+	if err := sn.Scan(src); err != nil {
+		return err
+	}
+	n.V, n.Valid = sn.V, sn.Valid
+	return nil
+}
+
+// Value implements driver.Valuer, V is converted the same way as it would be if it was passed as an argument.
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(n.V)
+}
+
+// MarshalJSON encodes n as JSON null or JSON of V.
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.V)
+}
+
+// UnmarshalJSON decodes JSON null as NULL and other values into V.
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*n = Null[T]{}
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.V); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+func (n Null[T]) isNull() {}
+
+// nullType is implemented by all Null types.
+type nullType interface {
+	isNull()
+}
+
+// isNullType reports whether t is a Null type, which makes its column nullable.
+func isNullType(t reflect.Type) bool {
+	return t.Implements(reflect.TypeOf((*nullType)(nil)).Elem())
+}
@@ -0,0 +1,69 @@
+package orm_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golobby/orm"
+	"github.com/stretchr/testify/assert"
+)
+
+type Member struct {
+	ID       int64
+	Nickname orm.Null[string]
+	Age      orm.Null[int32]
+	BannedAt orm.Null[time.Time]
+	// timestamps can be Null too.
+	CreatedAt orm.Null[time.Time]
+}
+
+func (m Member) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("members").Connection("default")
+}
+
+func TestNull(t *testing.T) {
+	t.Run("insert and find", func(t *testing.T) {
+		assert.NoError(t, setup())
+		_, err := orm.GetConnection("default").DB.Exec(`CREATE TABLE members (id INTEGER PRIMARY KEY, nickname text, age INTEGER, banned_at TIMESTAMP, created_at TIMESTAMP)`)
+		assert.NoError(t, err)
+
+		assert.NoError(t, orm.Insert(&Member{Nickname: orm.NewNull("gopher"), Age: orm.NewNull[int32](12)}))
+		m, err := orm.Find[Member](1)
+		assert.NoError(t, err)
+		assert.Equal(t, orm.NewNull("gopher"), m.Nickname)
+		assert.Equal(t, orm.NewNull[int32](12), m.Age)
+		assert.False(t, m.BannedAt.Valid)
+		assert.Nil(t, m.BannedAt.Ptr())
+		assert.True(t, m.CreatedAt.Valid)
+
+		count, err := orm.Query[Member]().Where(orm.Raw("banned_at IS NULL")).Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+	})
+	t.Run("json", func(t *testing.T) {
+		bs, err := json.Marshal(Member{ID: 1, Nickname: orm.NewNull("gopher")})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"ID":1,"Nickname":"gopher","Age":null,"BannedAt":null,"CreatedAt":null}`, string(bs))
+
+		var m Member
+		assert.NoError(t, json.Unmarshal([]byte(`{"Nickname":null,"Age":7}`), &m))
+		assert.False(t, m.Nickname.Valid)
+		assert.Equal(t, orm.NewNull[int32](7), m.Age)
+	})
+	t.Run("validations reject NOT NULL columns", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", ":memory:")
+		assert.NoError(t, err)
+		_, err = db.Exec(`CREATE TABLE members (id INTEGER PRIMARY KEY, nickname text NOT NULL, age INTEGER, banned_at TIMESTAMP, created_at TIMESTAMP)`)
+		assert.NoError(t, err)
+		err = orm.SetupConnections(orm.ConnectionConfig{
+			Name:                "default",
+			DB:                  db,
+			Dialect:             orm.Dialects.SQLite3,
+			Entities:            []orm.Entity{&Member{}},
+			DatabaseValidations: true,
+		})
+		assert.ErrorContains(t, err, "members.nickname")
+	})
+}
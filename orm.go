@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -45,6 +46,11 @@ type ConnectionConfig struct {
 	// Database validations, check if all tables exists and also table schemas contains all necessary columns.
 	// Check if all infered tables exist in your database
 	DatabaseValidations bool
+	// Interceptors receive every query that runs on this connection, you can use them for
+	// logging, instrumentation, etc.
+	Interceptors []Interceptor
+	// Metrics if set, receives measurements of every query that runs on this connection.
+	Metrics Metrics
 }
 
 // SetupConnections declares a new connections for ORM.
@@ -104,7 +110,10 @@ func setupConnection(config ConnectionConfig) error {
 	}
 
 	for _, entity := range config.Entities {
-		s := schemaOfHeavyReflectionStuff(entity)
+		s, err := schemaOfHeavyReflectionStuff(entity)
+		if err != nil {
+			return err
+		}
 		var configurator EntityConfigurator
 		entity.ConfigureEntity(&configurator)
 		schemas[configurator.table] = s
@@ -117,6 +126,10 @@ func setupConnection(config ConnectionConfig) error {
 		Schemas:             schemas,
 		DBSchema:            make(map[string][]columnSpec),
 		DatabaseValidations: config.DatabaseValidations,
+		interceptors:        append([]Interceptor(nil), config.Interceptors...),
+	}
+	if config.Metrics != nil {
+		s.AddInterceptor(metricsInterceptor{metrics: config.Metrics})
 	}
 
 	globalConnections[fmt.Sprintf("%s", config.Name)] = s
@@ -136,26 +149,38 @@ type Entity interface {
 // InsertAll given entities into database based on their ConfigureEntity
 // we can find table and also DB name.
 func InsertAll(objs ...Entity) error {
+	return InsertAllContext(context.Background(), objs...)
+}
+
+// InsertAllContext is like InsertAll but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func InsertAllContext(ctx context.Context, objs ...Entity) error {
 	if len(objs) == 0 {
 		return nil
 	}
-	s := getSchemaFor(objs[0])
+	s, err := getSchemaFor(objs[0])
+	if err != nil {
+		return err
+	}
+	conn, err := s.getConnection()
+	if err != nil {
+		return err
+	}
 	cols := s.Columns(false)
 	var values [][]interface{}
 	for _, obj := range objs {
 		createdAtF := s.createdAt()
 		if createdAtF != nil {
-			genericSet(obj, createdAtF.Name, sql.NullTime{Time: time.Now(), Valid: true})
+			genericSet(s, obj, createdAtF.Name, timestampFor(createdAtF, time.Now()))
 		}
 		updatedAtF := s.updatedAt()
 		if updatedAtF != nil {
-			genericSet(obj, updatedAtF.Name, sql.NullTime{Time: time.Now(), Valid: true})
+			genericSet(s, obj, updatedAtF.Name, timestampFor(updatedAtF, time.Now()))
 		}
-		values = append(values, genericValuesOf(obj, false))
+		values = append(values, genericValuesOf(s, obj, false))
 	}
 
 	is := insertStmt{
-		PlaceHolderGenerator: s.getDialect().PlaceHolderGenerator,
+		PlaceHolderGenerator: conn.Dialect.PlaceHolderGenerator,
 		Table:                s.getTable(),
 		Columns:              cols,
 		Values:               values,
@@ -163,7 +188,7 @@ func InsertAll(objs ...Entity) error {
 
 	q, args := is.ToSql()
 
-	_, err := s.getConnection().exec(q, args...)
+	_, err = conn.exec(ctx, s.Table, q, args...)
 	if err != nil {
 		return err
 	}
@@ -173,32 +198,44 @@ func InsertAll(objs ...Entity) error {
 // Insert given entity into database based on their ConfigureEntity
 // we can find table and also DB name.
 func Insert(o Entity) error {
-	s := getSchemaFor(o)
+	return InsertContext(context.Background(), o)
+}
+
+// InsertContext is like Insert but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func InsertContext(ctx context.Context, o Entity) error {
+	s, err := getSchemaFor(o)
+	if err != nil {
+		return err
+	}
+	conn, err := s.getConnection()
+	if err != nil {
+		return err
+	}
 	cols := s.Columns(false)
 	var values [][]interface{}
 	createdAtF := s.createdAt()
 	if createdAtF != nil {
-		genericSet(o, createdAtF.Name, sql.NullTime{Time: time.Now(), Valid: true})
+		genericSet(s, o, createdAtF.Name, timestampFor(createdAtF, time.Now()))
 	}
 	updatedAtF := s.updatedAt()
 	if updatedAtF != nil {
-		genericSet(o, updatedAtF.Name, sql.NullTime{Time: time.Now(), Valid: true})
+		genericSet(s, o, updatedAtF.Name, timestampFor(updatedAtF, time.Now()))
 	}
-	values = append(values, genericValuesOf(o, false))
+	values = append(values, genericValuesOf(s, o, false))
 
 	is := insertStmt{
-		PlaceHolderGenerator: s.getDialect().PlaceHolderGenerator,
+		PlaceHolderGenerator: conn.Dialect.PlaceHolderGenerator,
 		Table:                s.getTable(),
 		Columns:              cols,
 		Values:               values,
 	}
 
-	if s.getDialect().DriverName == "postgres" {
+	if conn.Dialect.DriverName == "postgres" {
 		is.Returning = s.pkName()
 	}
 	q, args := is.ToSql()
 
-	res, err := s.getConnection().exec(q, args...)
+	res, err := conn.exec(ctx, s.Table, q, args...)
 	if err != nil {
 		return err
 	}
@@ -232,18 +269,35 @@ func isZero(val interface{}) bool {
 // primary key is zero value we will
 // insert it.
 func Save(obj Entity) error {
-	if isZero(getSchemaFor(obj).getPK(obj)) {
-		return Insert(obj)
+	return SaveContext(context.Background(), obj)
+}
+
+// SaveContext is like Save but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func SaveContext(ctx context.Context, obj Entity) error {
+	s, err := getSchemaFor(obj)
+	if err != nil {
+		return err
+	}
+	if isZero(s.getPK(obj)) {
+		return InsertContext(ctx, obj)
 	} else {
-		return Update(obj)
+		return UpdateContext(ctx, obj)
 	}
 }
 
 // Find finds the Entity you want based on generic type and primary key you passed.
 func Find[T Entity](id interface{}) (T, error) {
+	return FindContext[T](context.Background(), id)
+}
+
+// FindContext is like Find but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func FindContext[T Entity](ctx context.Context, id interface{}) (T, error) {
 	var q string
 	out := new(T)
-	md := getSchemaFor(*out)
+	md, err := getSchemaFor(*out)
+	if err != nil {
+		return *out, err
+	}
 	q, args, err := NewQueryBuilder[T](md).
 		SetDialect(md.getDialect()).
 		Table(md.Table).
@@ -253,7 +307,7 @@ func Find[T Entity](id interface{}) (T, error) {
 	if err != nil {
 		return *out, err
 	}
-	err = bind[T](out, q, args)
+	err = bind[T](ctx, out, q, args)
 
 	if err != nil {
 		return *out, err
@@ -262,10 +316,10 @@ func Find[T Entity](id interface{}) (T, error) {
 	return *out, nil
 }
 
-func toKeyValues(obj Entity, withPK bool) []any {
+func toKeyValues(s *schema, obj Entity, withPK bool) []any {
 	var tuples []any
-	vs := genericValuesOf(obj, withPK)
-	cols := getSchemaFor(obj).Columns(withPK)
+	vs := genericValuesOf(s, obj, withPK)
+	cols := s.Columns(withPK)
 	for i, col := range cols {
 		tuples = append(tuples, col, vs[i])
 	}
@@ -274,34 +328,67 @@ func toKeyValues(obj Entity, withPK bool) []any {
 
 // Update given Entity in database.
 func Update(obj Entity) error {
-	s := getSchemaFor(obj)
+	return UpdateContext(context.Background(), obj)
+}
+
+// UpdateContext is like Update but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func UpdateContext(ctx context.Context, obj Entity) error {
+	s, err := getSchemaFor(obj)
+	if err != nil {
+		return err
+	}
+	conn, err := s.getConnection()
+	if err != nil {
+		return err
+	}
 	q, args, err := NewQueryBuilder[Entity](s).
-		SetDialect(s.getDialect()).
-		Set(toKeyValues(obj, false)...).
-		Where(s.pkName(), genericGetPKValue(obj)).Table(s.Table).ToSql()
+		SetDialect(conn.Dialect).
+		Set(toKeyValues(s, obj, false)...).
+		Where(s.pkName(), genericGetPKValue(s, obj)).Table(s.Table).ToSql()
 
 	if err != nil {
 		return err
 	}
-	_, err = s.getConnection().exec(q, args...)
+	_, err = conn.exec(ctx, s.Table, q, args...)
 	return err
 }
 
 // Delete given Entity from database
 func Delete(obj Entity) error {
-	s := getSchemaFor(obj)
-	genericSet(obj, "deleted_at", sql.NullTime{Time: time.Now(), Valid: true})
-	query, args, err := NewQueryBuilder[Entity](s).SetDialect(s.getDialect()).Table(s.Table).Where(s.pkName(), genericGetPKValue(obj)).SetDelete().ToSql()
+	return DeleteContext(context.Background(), obj)
+}
+
+// DeleteContext is like Delete but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func DeleteContext(ctx context.Context, obj Entity) error {
+	s, err := getSchemaFor(obj)
+	if err != nil {
+		return err
+	}
+	conn, err := s.getConnection()
+	if err != nil {
+		return err
+	}
+	if deletedAtF := s.deletedAt(); deletedAtF != nil {
+		genericSet(s, obj, deletedAtF.Name, timestampFor(deletedAtF, time.Now()))
+	}
+	query, args, err := NewQueryBuilder[Entity](s).SetDialect(conn.Dialect).Table(s.Table).Where(s.pkName(), genericGetPKValue(s, obj)).SetDelete().ToSql()
 	if err != nil {
 		return err
 	}
-	_, err = s.getConnection().exec(query, args...)
+	_, err = conn.exec(ctx, s.Table, query, args...)
 	return err
 }
 
-func bind[T Entity](output interface{}, q string, args []interface{}) error {
-	outputMD := getSchemaFor(*new(T))
-	rows, err := outputMD.getConnection().query(q, args...)
+func bind[T Entity](ctx context.Context, output interface{}, q string, args []interface{}) error {
+	outputMD, err := getSchemaFor(*new(T))
+	if err != nil {
+		return err
+	}
+	conn, err := outputMD.getConnection()
+	if err != nil {
+		return err
+	}
+	rows, err := conn.query(ctx, outputMD.Table, q, args...)
 	if err != nil {
 		return err
 	}
@@ -329,21 +416,27 @@ type HasManyConfig struct {
 // HasMany[Comment](&Post{})
 // is for Post HasMany Comment relationship.
 func HasMany[PROPERTY Entity](owner Entity) *QueryBuilder[PROPERTY] {
-	outSchema := getSchemaFor(*new(PROPERTY))
+	outSchema, err := getSchemaFor(*new(PROPERTY))
+	if err != nil {
+		return queryBuilderWithError[PROPERTY](err)
+	}
 
 	q := NewQueryBuilder[PROPERTY](outSchema)
+	s, err := getSchemaFor(owner)
+	if err != nil {
+		return queryBuilderWithError[PROPERTY](err)
+	}
 	// getting config from our cache
-	c, ok := getSchemaFor(owner).relations[outSchema.Table].(HasManyConfig)
+	c, ok := s.relations[outSchema.Table].(HasManyConfig)
 	if !ok {
-		q.err = fmt.Errorf("wrong config passed for HasMany")
+		q.err = fmt.Errorf("wrong config passed for HasMany: %w", ErrRelationNotConfigured)
 	}
 
-	s := getSchemaFor(owner)
 	return q.
 		SetDialect(s.getDialect()).
 		Table(c.PropertyTable).
 		Select(outSchema.Columns(true)...).
-		Where(c.PropertyForeignKey, genericGetPKValue(owner))
+		Where(c.PropertyForeignKey, genericGetPKValue(s, owner))
 }
 
 // HasOneConfig contains all information we need for a HasOne relationship,
@@ -366,11 +459,18 @@ type HasOneConfig struct {
 // HasOne[HeaderPicture](&Post{})
 // is for Post HasOne HeaderPicture relationship.
 func HasOne[PROPERTY Entity](owner Entity) *QueryBuilder[PROPERTY] {
-	property := getSchemaFor(*new(PROPERTY))
+	property, err := getSchemaFor(*new(PROPERTY))
+	if err != nil {
+		return queryBuilderWithError[PROPERTY](err)
+	}
+	ownerSchema, err := getSchemaFor(owner)
+	if err != nil {
+		return queryBuilderWithError[PROPERTY](err)
+	}
 	q := NewQueryBuilder[PROPERTY](property)
-	c, ok := getSchemaFor(owner).relations[property.Table].(HasOneConfig)
+	c, ok := ownerSchema.relations[property.Table].(HasOneConfig)
 	if !ok {
-		q.err = fmt.Errorf("wrong config passed for HasOne")
+		q.err = fmt.Errorf("wrong config passed for HasOne: %w", ErrRelationNotConfigured)
 	}
 
 	// settings default config Values
@@ -378,7 +478,7 @@ func HasOne[PROPERTY Entity](owner Entity) *QueryBuilder[PROPERTY] {
 		SetDialect(property.getDialect()).
 		Table(c.PropertyTable).
 		Select(property.Columns(true)...).
-		Where(c.PropertyForeignKey, genericGetPKValue(owner))
+		Where(c.PropertyForeignKey, genericGetPKValue(ownerSchema, owner))
 }
 
 // BelongsToConfig contains all information we need for a BelongsTo relationship
@@ -404,21 +504,34 @@ type BelongsToConfig struct {
 // OWNER type parameter and property argument, so
 // property BelongsTo OWNER.
 func BelongsTo[OWNER Entity](property Entity) *QueryBuilder[OWNER] {
-	owner := getSchemaFor(*new(OWNER))
+	owner, err := getSchemaFor(*new(OWNER))
+	if err != nil {
+		return queryBuilderWithError[OWNER](err)
+	}
+	propertySchema, err := getSchemaFor(property)
+	if err != nil {
+		return queryBuilderWithError[OWNER](err)
+	}
 	q := NewQueryBuilder[OWNER](owner)
-	c, ok := getSchemaFor(property).relations[owner.Table].(BelongsToConfig)
+	c, ok := propertySchema.relations[owner.Table].(BelongsToConfig)
 	if !ok {
-		q.err = fmt.Errorf("wrong config passed for BelongsTo")
+		q.err = fmt.Errorf("wrong config passed for BelongsTo: %w", ErrRelationNotConfigured)
 	}
 
+	// index among values of persisted fields, virtual ones have no value.
 	ownerIDidx := 0
-	for idx, field := range owner.fields {
+	idx := 0
+	for _, field := range propertySchema.fields {
+		if field.Virtual {
+			continue
+		}
 		if field.Name == c.LocalForeignKey {
 			ownerIDidx = idx
 		}
+		idx++
 	}
 
-	ownerID := genericValuesOf(property, true)[ownerIDidx]
+	ownerID := genericValuesOf(propertySchema, property, true)[ownerIDidx]
 
 	return q.
 		SetDialect(owner.getDialect()).
@@ -463,63 +576,92 @@ type BelongsToManyConfig struct {
 // BelongsToMany configures a QueryBuilder for a BelongsToMany relationship
 func BelongsToMany[OWNER Entity](property Entity) *QueryBuilder[OWNER] {
 	out := *new(OWNER)
-	outSchema := getSchemaFor(out)
+	outSchema, err := getSchemaFor(out)
+	if err != nil {
+		return queryBuilderWithError[OWNER](err)
+	}
+	propertySchema, err := getSchemaFor(property)
+	if err != nil {
+		return queryBuilderWithError[OWNER](err)
+	}
 	q := NewQueryBuilder[OWNER](outSchema)
-	c, ok := getSchemaFor(property).relations[outSchema.Table].(BelongsToManyConfig)
+	c, ok := propertySchema.relations[outSchema.Table].(BelongsToManyConfig)
 	if !ok {
-		q.err = fmt.Errorf("wrong config passed for HasMany")
+		q.err = fmt.Errorf("wrong config passed for BelongsToMany: %w", ErrRelationNotConfigured)
 	}
 	return q.
 		Select(outSchema.Columns(true)...).
 		Table(outSchema.Table).
 		WhereIn(c.OwnerLookupColumn, Raw(fmt.Sprintf(`SELECT %s FROM %s WHERE %s = ?`,
 			c.IntermediatePropertyID,
-			c.IntermediateTable, c.IntermediateOwnerID), genericGetPKValue(property)))
+			c.IntermediateTable, c.IntermediateOwnerID), genericGetPKValue(propertySchema, property)))
 }
 
 // Add adds `items` to `to` using relations defined between items and to in ConfigureEntity method of `to`.
 func Add(to Entity, items ...Entity) error {
+	return AddContext(context.Background(), to, items...)
+}
+
+// AddContext is like Add but runs queries using ctx, so they run inside transaction of ctx if it has one.
+func AddContext(ctx context.Context, to Entity, items ...Entity) error {
 	if len(items) == 0 {
 		return nil
 	}
-	rels := getSchemaFor(to).relations
-	tname := getSchemaFor(items[0]).Table
-	c, ok := rels[tname]
+	toSchema, err := getSchemaFor(to)
+	if err != nil {
+		return err
+	}
+	itemSchema, err := getSchemaFor(items[0])
+	if err != nil {
+		return err
+	}
+	rels := toSchema.relations
+	c, ok := rels[itemSchema.Table]
 	if !ok {
-		return fmt.Errorf("no config found for given to and item...")
+		return fmt.Errorf("no config found for given to and item: %w", ErrRelationNotConfigured)
 	}
 	switch c.(type) {
 	case HasManyConfig:
-		return addProperty(to, items...)
+		return addProperty(ctx, to, items...)
 	case HasOneConfig:
-		return addProperty(to, items[0])
+		return addProperty(ctx, to, items[0])
 	case BelongsToManyConfig:
-		return addM2M(to, items...)
+		return addM2M(ctx, to, items...)
 	default:
-		return fmt.Errorf("cannot add for relation: %T", rels[getSchemaFor(items[0]).Table])
+		return fmt.Errorf("cannot add for relation: %T", rels[itemSchema.Table])
 	}
 }
 
-func addM2M(to Entity, items ...Entity) error {
+func addM2M(ctx context.Context, to Entity, items ...Entity) error {
 	//TODO: Optimize this
-	rels := getSchemaFor(to).relations
-	tname := getSchemaFor(items[0]).Table
-	c := rels[tname].(BelongsToManyConfig)
+	toSchema, err := getSchemaFor(to)
+	if err != nil {
+		return err
+	}
+	itemSchema, err := getSchemaFor(items[0])
+	if err != nil {
+		return err
+	}
+	conn, err := itemSchema.getConnection()
+	if err != nil {
+		return err
+	}
+	c := toSchema.relations[itemSchema.Table].(BelongsToManyConfig)
 	var values [][]interface{}
-	ownerPk := genericGetPKValue(to)
+	ownerPk := genericGetPKValue(toSchema, to)
 	for _, item := range items {
-		pk := genericGetPKValue(item)
+		pk := genericGetPKValue(itemSchema, item)
 		if isZero(pk) {
-			err := Insert(item)
+			err := InsertContext(ctx, item)
 			if err != nil {
 				return err
 			}
-			pk = genericGetPKValue(item)
+			pk = genericGetPKValue(itemSchema, item)
 		}
 		values = append(values, []interface{}{ownerPk, pk})
 	}
 	i := insertStmt{
-		PlaceHolderGenerator: getSchemaFor(to).getDialect().PlaceHolderGenerator,
+		PlaceHolderGenerator: toSchema.getDialect().PlaceHolderGenerator,
 		Table:                c.IntermediateTable,
 		Columns:              []string{c.IntermediateOwnerID, c.IntermediatePropertyID},
 		Values:               values,
@@ -527,7 +669,7 @@ func addM2M(to Entity, items ...Entity) error {
 
 	q, args := i.ToSql()
 
-	_, err := getConnectionFor(items[0]).DB.Exec(q, args...)
+	_, err = conn.exec(ctx, c.IntermediateTable, q, args...)
 	if err != nil {
 		return err
 	}
@@ -536,10 +678,13 @@ func addM2M(to Entity, items ...Entity) error {
 }
 
 // addHasMany(Post, comments)
-func addProperty(to Entity, items ...Entity) error {
+func addProperty(ctx context.Context, to Entity, items ...Entity) error {
 	var lastTable string
 	for _, obj := range items {
-		s := getSchemaFor(obj)
+		s, err := getSchemaFor(obj)
+		if err != nil {
+			return err
+		}
 		if lastTable == "" {
 			lastTable = s.Table
 		} else {
@@ -548,26 +693,42 @@ func addProperty(to Entity, items ...Entity) error {
 			}
 		}
 	}
+	toSchema, err := getSchemaFor(to)
+	if err != nil {
+		return err
+	}
+	itemSchema, err := getSchemaFor(items[0])
+	if err != nil {
+		return err
+	}
+	conn, err := itemSchema.getConnection()
+	if err != nil {
+		return err
+	}
+	belongsTo, ok := itemSchema.relations[toSchema.Table].(BelongsToConfig)
+	if !ok {
+		return fmt.Errorf("%s should have a BelongsTo relation to %s: %w", itemSchema.Table, toSchema.Table, ErrRelationNotConfigured)
+	}
 	i := insertStmt{
-		PlaceHolderGenerator: getSchemaFor(to).getDialect().PlaceHolderGenerator,
-		Table:                getSchemaFor(items[0]).getTable(),
+		PlaceHolderGenerator: toSchema.getDialect().PlaceHolderGenerator,
+		Table:                itemSchema.getTable(),
 	}
 	ownerPKIdx := -1
-	ownerPKName := getSchemaFor(items[0]).relations[getSchemaFor(to).Table].(BelongsToConfig).LocalForeignKey
-	for idx, col := range getSchemaFor(items[0]).Columns(false) {
+	ownerPKName := belongsTo.LocalForeignKey
+	for idx, col := range itemSchema.Columns(false) {
 		if col == ownerPKName {
 			ownerPKIdx = idx
 		}
 	}
 
-	ownerPK := genericGetPKValue(to)
+	ownerPK := genericGetPKValue(toSchema, to)
 	if ownerPKIdx != -1 {
-		cols := getSchemaFor(items[0]).Columns(false)
+		cols := itemSchema.Columns(false)
 		i.Columns = append(i.Columns, cols...)
 		// Owner PK is present in the items struct
 		for _, item := range items {
-			vals := genericValuesOf(item, false)
-			if cols[ownerPKIdx] != getSchemaFor(items[0]).relations[getSchemaFor(to).Table].(BelongsToConfig).LocalForeignKey {
+			vals := genericValuesOf(itemSchema, item, false)
+			if cols[ownerPKIdx] != belongsTo.LocalForeignKey {
 				return fmt.Errorf("owner pk idx is not correct")
 			}
 			vals[ownerPKIdx] = ownerPK
@@ -575,13 +736,13 @@ func addProperty(to Entity, items ...Entity) error {
 		}
 	} else {
 		ownerPKIdx = 0
-		cols := getSchemaFor(items[0]).Columns(false)
+		cols := itemSchema.Columns(false)
 		cols = append(cols[:ownerPKIdx+1], cols[ownerPKIdx:]...)
-		cols[ownerPKIdx] = getSchemaFor(items[0]).relations[getSchemaFor(to).Table].(BelongsToConfig).LocalForeignKey
+		cols[ownerPKIdx] = belongsTo.LocalForeignKey
 		i.Columns = append(i.Columns, cols...)
 		for _, item := range items {
-			vals := genericValuesOf(item, false)
-			if cols[ownerPKIdx] != getSchemaFor(items[0]).relations[getSchemaFor(to).Table].(BelongsToConfig).LocalForeignKey {
+			vals := genericValuesOf(itemSchema, item, false)
+			if cols[ownerPKIdx] != belongsTo.LocalForeignKey {
 				return fmt.Errorf("owner pk idx is not correct")
 			}
 			vals = append(vals[:ownerPKIdx+1], vals[ownerPKIdx:]...)
@@ -592,7 +753,7 @@ func addProperty(to Entity, items ...Entity) error {
 
 	q, args := i.ToSql()
 
-	_, err := getConnectionFor(items[0]).DB.Exec(q, args...)
+	_, err = conn.exec(ctx, itemSchema.Table, q, args...)
 	if err != nil {
 		return err
 	}
@@ -603,7 +764,10 @@ func addProperty(to Entity, items ...Entity) error {
 
 // Query creates a new QueryBuilder for given type parameter, sets dialect and table as well.
 func Query[E Entity]() *QueryBuilder[E] {
-	s := getSchemaFor(*new(E))
+	s, err := getSchemaFor(*new(E))
+	if err != nil {
+		return queryBuilderWithError[E](err)
+	}
 	q := NewQueryBuilder[E](s)
 	q.SetDialect(s.getDialect()).Table(s.Table)
 	return q
@@ -611,9 +775,22 @@ func Query[E Entity]() *QueryBuilder[E] {
 
 // ExecRaw executes given query string and arguments on given type parameter database connection.
 func ExecRaw[E Entity](q string, args ...interface{}) (int64, int64, error) {
+	return ExecRawContext[E](context.Background(), q, args...)
+}
+
+// ExecRawContext is like ExecRaw but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func ExecRawContext[E Entity](ctx context.Context, q string, args ...interface{}) (int64, int64, error) {
 	e := new(E)
 
-	res, err := getSchemaFor(*e).getSQLDB().Exec(q, args...)
+	s, err := getSchemaFor(*e)
+	if err != nil {
+		return 0, 0, err
+	}
+	conn, err := s.getConnection()
+	if err != nil {
+		return 0, 0, err
+	}
+	res, err := conn.exec(ctx, "", q, args...)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -633,13 +810,70 @@ func ExecRaw[E Entity](q string, args ...interface{}) (int64, int64, error) {
 
 // QueryRaw queries given query string and arguments on given type parameter database connection.
 func QueryRaw[OUTPUT Entity](q string, args ...interface{}) ([]OUTPUT, error) {
+	return QueryRawContext[OUTPUT](context.Background(), q, args...)
+}
+
+// QueryRawContext is like QueryRaw but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func QueryRawContext[OUTPUT Entity](ctx context.Context, q string, args ...interface{}) ([]OUTPUT, error) {
 	o := new(OUTPUT)
-	rows, err := getSchemaFor(*o).getSQLDB().Query(q, args...)
+	s, err := getSchemaFor(*o)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.getConnection()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.query(ctx, "", q, args...)
 	if err != nil {
 		return nil, err
 	}
 	var output []OUTPUT
-	err = newBinder(getSchemaFor(*o)).bind(rows, &output)
+	err = newBinder(s).bind(rows, &output)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// QueryRawMaps queries given query string and arguments on connection with given name and returns
+// each row as a map of column name to its value.
+func QueryRawMaps(connection string, q string, args ...interface{}) ([]map[string]interface{}, error) {
+	return QueryRawMapsContext(context.Background(), connection, q, args...)
+}
+
+// QueryRawMapsContext is like QueryRawMaps but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func QueryRawMapsContext(ctx context.Context, connection string, q string, args ...interface{}) ([]map[string]interface{}, error) {
+	conn, exists := globalConnections[connection]
+	if !exists {
+		return nil, ErrNoConnection
+	}
+	rows, err := conn.query(ctx, "", q, args...)
+	if err != nil {
+		return nil, err
+	}
+	return bindToMap(rows)
+}
+
+// QueryRawInto queries given query string and arguments on connection with given name and binds results
+// into T, which can be any struct, whose fields are matched with columns using their db tag or snake
+// case name, or a scalar type when query returns a single column. Use QueryRaw for entities.
+func QueryRawInto[T any](connection string, q string, args ...interface{}) ([]T, error) {
+	return QueryRawIntoContext[T](context.Background(), connection, q, args...)
+}
+
+// QueryRawIntoContext is like QueryRawInto but runs query using ctx, so it runs inside transaction of ctx if it has one.
+func QueryRawIntoContext[T any](ctx context.Context, connection string, q string, args ...interface{}) ([]T, error) {
+	conn, exists := globalConnections[connection]
+	if !exists {
+		return nil, ErrNoConnection
+	}
+	rows, err := conn.query(ctx, "", q, args...)
+	if err != nil {
+		return nil, err
+	}
+	var output []T
+	err = newBinder(nil).bind(rows, &output)
 	if err != nil {
 		return nil, err
 	}
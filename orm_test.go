@@ -1,7 +1,9 @@
 package orm_test
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
 
 	"github.com/golobby/orm"
@@ -511,7 +513,7 @@ func TestQuery(t *testing.T) {
 		affected, err := orm.Query[Post]().WherePK(1).Delete()
 		assert.NoError(t, err)
 		assert.EqualValues(t, 1, affected)
-		count, err := orm.Query[Post]().WherePK(1).Count().Get()
+		count, err := orm.Query[Post]().WherePK(1).Count()
 		assert.NoError(t, err)
 		assert.EqualValues(t, 0, count)
 	})
@@ -520,11 +522,181 @@ func TestQuery(t *testing.T) {
 		err := setup()
 		assert.NoError(t, err)
 
-		count, err := orm.Query[Post]().WherePK(1).Count().Get()
+		count, err := orm.Query[Post]().WherePK(1).Count()
 		assert.NoError(t, err)
 		assert.EqualValues(t, 0, count)
 	})
 
+	t.Run("count distinct", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 2"}))
+
+		count, err := orm.Query[Post]().Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, count)
+
+		count, err = orm.Query[Post]().Select("body").Distinct().Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+
+		count, err = orm.Query[Post]().Select("id", "body").Distinct().Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, count)
+	})
+
+	t.Run("count grouped and limited", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, orm.Save(&Post{BodyText: []string{"body 1", "body 2"}[i%2]}))
+		}
+
+		count, err := orm.Query[Post]().Select("body").GroupBy("body").Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+
+		count, err = orm.Query[Post]().Limit(2).Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+
+		count, err = orm.Query[Post]().Limit(10).Offset(3).Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+
+		sum, err := orm.Sum[int64](orm.Query[Post]().OrderBy("id", orm.DESC).Limit(2), "id")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 9, sum)
+	})
+
+	t.Run("aggregates", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		sum, err := orm.Sum[int64](orm.Query[Post](), "id")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, sum)
+
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 2"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 3"}))
+
+		sum, err = orm.Sum[int64](orm.Query[Post](), "id")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 6, sum)
+
+		avg, err := orm.Avg[float64](orm.Query[Post]().Where("id", "<", 3), "id")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1.5, avg)
+
+		min, err := orm.Min[int64](orm.Query[Post]().OrderBy("id", orm.DESC), "id")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, min)
+
+		max, err := orm.Max[string](orm.Query[Post](), "body")
+		assert.NoError(t, err)
+		assert.Equal(t, "body 3", max)
+	})
+
+	t.Run("union", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 2"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 3"}))
+
+		posts, err := orm.Query[Post]().Where("id", 1).
+			Union(orm.Query[Post]().Where("id", ">", 2)).
+			OrderBy("id", orm.DESC).
+			All()
+		assert.NoError(t, err)
+		assert.Len(t, posts, 2)
+		assert.EqualValues(t, 3, posts[0].ID)
+		assert.EqualValues(t, 1, posts[1].ID)
+
+		count, err := orm.Query[Post]().Where("id", "<", 3).
+			Except(orm.Query[Post]().Where("id", 2)).
+			Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+	})
+
+	t.Run("with recursive", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		for _, title := range []string{"first", "second", "third", "fourth"} {
+			assert.NoError(t, orm.Save(&Category{Title: title}))
+		}
+
+		// walks categories with consecutive ids starting from 2.
+		categories, err := orm.Query[Category]().
+			WithRecursive("chain",
+				orm.Query[Category]().Where("id", 2),
+				orm.Query[Category]().Select("categories.id", "categories.title").InnerJoin("chain", "categories.id", "chain.id + 1"),
+			).
+			Table("chain").
+			OrderBy("id", orm.ASC).
+			All()
+		assert.NoError(t, err)
+		assert.Len(t, categories, 3)
+		assert.Equal(t, "second", categories[0].Title)
+		assert.Equal(t, "fourth", categories[2].Title)
+	})
+
+	t.Run("subqueries", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 2"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 3"}))
+		assert.NoError(t, orm.Save(&Comment{PostID: 2, Body: "comment"}))
+
+		posts, err := orm.Query[Post]().WhereIn("id", orm.Query[Comment]().Select("post_id")).All()
+		assert.NoError(t, err)
+		assert.Len(t, posts, 1)
+		assert.EqualValues(t, 2, posts[0].ID)
+
+		count, err := orm.Query[Post]().
+			WhereNotExists(orm.Query[Comment]().Where(orm.Raw("comments.post_id = posts.id"))).
+			Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+	})
+
+	t.Run("join into nested struct", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 2"}))
+		assert.NoError(t, orm.Save(&AuthorEmail{Email: "amirreza@example.com"}))
+		_, _, err = orm.ExecRaw[AuthorEmail](`UPDATE emails SET post_id = ?`, 2)
+		assert.NoError(t, err)
+
+		type PostWithEmail struct {
+			Post
+			Email AuthorEmail
+		}
+		posts, err := orm.Query[PostWithEmail]().
+			Alias("p").
+			Select("p.id", "p.body", "e.id AS email__id", "e.email AS email__email").
+			JoinOn(orm.JoinTypeInner, "emails", "e", orm.On("e.post_id", "p.id").Where("e.email", "LIKE", "%@example.com")).
+			All()
+		assert.NoError(t, err)
+		assert.Len(t, posts, 1)
+		assert.EqualValues(t, 2, posts[0].ID)
+		assert.Equal(t, "body 2", posts[0].BodyText)
+		assert.EqualValues(t, 1, posts[0].Email.ID)
+		assert.Equal(t, "amirreza@example.com", posts[0].Email.Email)
+	})
+
 	t.Run("latest", func(t *testing.T) {
 		err := setup()
 		assert.NoError(t, err)
@@ -578,4 +750,404 @@ func TestSetup(t *testing.T) {
 		assert.Error(t, err)
 
 	})
+	t.Run("virtual fields have no column", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", ":memory:")
+		assert.NoError(t, err)
+		_, err = db.Exec(`CREATE TABLE drafts (id INTEGER PRIMARY KEY, created_at TIMESTAMP, updated_at TIMESTAMP, deleted_at TIMESTAMP, body text)`)
+		assert.NoError(t, err)
+
+		err = orm.SetupConnections(orm.ConnectionConfig{
+			Name:                "default",
+			DB:                  db,
+			Dialect:             orm.Dialects.SQLite3,
+			Entities:            []orm.Entity{&Draft{}},
+			DatabaseValidations: true,
+		})
+		assert.NoError(t, err)
+	})
+	t.Run("self referencing virtual fields", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", ":memory:")
+		assert.NoError(t, err)
+		_, err = db.Exec(`CREATE TABLE folders (id INTEGER PRIMARY KEY, name text, parent_id INTEGER)`)
+		assert.NoError(t, err)
+
+		err = orm.SetupConnections(orm.ConnectionConfig{
+			Name:                "default",
+			DB:                  db,
+			Dialect:             orm.Dialects.SQLite3,
+			Entities:            []orm.Entity{&Folder{}},
+			DatabaseValidations: true,
+		})
+		assert.NoError(t, err)
+
+		folder := &Folder{Name: "books"}
+		assert.NoError(t, orm.Insert(folder))
+		found, err := orm.Find[Folder](folder.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "books", found.Name)
+		assert.Nil(t, found.Parent)
+	})
+}
+
+func TestProjection(t *testing.T) {
+	assert.NoError(t, setup())
+	assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+	assert.NoError(t, orm.Save(&Post{BodyText: "body 2"}))
+	assert.NoError(t, orm.Save(&Comment{PostID: 2, Body: "comment"}))
+
+	type PostSummary struct {
+		ID      int64
+		Content string `db:"body"`
+		Ignored string `db:"-"`
+	}
+
+	t.Run("dto", func(t *testing.T) {
+		summaries, err := orm.Select[PostSummary](orm.Query[Post]().Where("id", ">", 0).OrderBy("id", orm.DESC)).All()
+		assert.NoError(t, err)
+		assert.Equal(t, []PostSummary{{ID: 2, Content: "body 2"}, {ID: 1, Content: "body 1"}}, summaries)
+
+		summary, err := orm.Select[*PostSummary](orm.Query[Post]().Where("id", 1)).Get()
+		assert.NoError(t, err)
+		assert.Equal(t, "body 1", summary.Content)
+	})
+
+	t.Run("scalars", func(t *testing.T) {
+		bodies, err := orm.Pluck[string](orm.Query[Post]().OrderBy("id", orm.ASC), "body")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"body 1", "body 2"}, bodies)
+
+		body, err := orm.Select[string](orm.Query[Post]().Select("body").Where("id", 2)).Get()
+		assert.NoError(t, err)
+		assert.Equal(t, "body 2", body)
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		type PostComments struct {
+			PostID int64
+			Total  int64 `db:"comments_count"`
+		}
+		rows, err := orm.QueryRawInto[PostComments]("default", `SELECT post_id, COUNT(*) AS comments_count FROM comments GROUP BY post_id`)
+		assert.NoError(t, err)
+		assert.Equal(t, []PostComments{{PostID: 2, Total: 1}}, rows)
+
+		ids, err := orm.QueryRawInto[int64]("default", `SELECT id FROM posts ORDER BY id`)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, ids)
+
+		_, err = orm.QueryRawInto[int64]("unknown", `SELECT id FROM posts`)
+		assert.ErrorIs(t, err, orm.ErrNoConnection)
+	})
+}
+
+func TestMaps(t *testing.T) {
+	assert.NoError(t, setup())
+	assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+	assert.NoError(t, orm.Save(&Post{BodyText: "body 2"}))
+
+	ms, err := orm.Query[Post]().Select("id", "body").OrderBy("id", orm.ASC).AllMaps()
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": int64(1), "body": "body 1"}, {"id": int64(2), "body": "body 2"}}, ms)
+
+	m, err := orm.Query[Post]().Select("body").Where("id", 2).GetMap()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"body": "body 2"}, m)
+
+	_, err = orm.Query[Post]().Where("id", 3).GetMap()
+	assert.ErrorIs(t, err, orm.ErrNotFound)
+
+	ms, err = orm.QueryRawMaps("default", `SELECT COUNT(*) AS total FROM posts WHERE id > ?`, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"total": int64(2)}}, ms)
+}
+
+type Draft struct {
+	ID int64
+	orm.Timestamps
+	Body      string
+	WordCount int `orm:"ignore"`
+	Comments  []Comment
+	Author    *AuthorEmail `orm:"ignore"`
+}
+
+func (d Draft) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("drafts").Connection("default")
+	e.Field("Comments").IsVirtual()
+}
+
+type Folder struct {
+	ID       int64
+	Name     string
+	ParentID int64
+	Parent   *Folder `orm:"ignore"`
+	Root     *Folder
+}
+
+func (f Folder) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("folders").Connection("default")
+	e.Field("Root").IsVirtual()
+}
+
+func TestVirtualFields(t *testing.T) {
+	assert.NoError(t, setup())
+	_, err := orm.GetConnection("default").DB.Exec(`CREATE TABLE drafts (id INTEGER PRIMARY KEY, created_at TIMESTAMP, updated_at TIMESTAMP, deleted_at TIMESTAMP, body text)`)
+	assert.NoError(t, err)
+
+	draft := &Draft{Body: "first draft", WordCount: 2, Comments: []Comment{{Body: "nice"}}}
+	assert.NoError(t, orm.Insert(draft))
+	assert.Equal(t, int64(1), draft.ID)
+
+	draft.Body = "second draft"
+	assert.NoError(t, orm.Update(draft))
+
+	found, err := orm.Find[Draft](1)
+	assert.NoError(t, err)
+	assert.Equal(t, "second draft", found.Body)
+	assert.True(t, found.CreatedAt.Valid)
+	assert.Zero(t, found.WordCount)
+	assert.Nil(t, found.Comments)
+	assert.Nil(t, found.Author)
+
+	drafts, err := orm.Query[Draft]().Where("body", "second draft").All()
+	assert.NoError(t, err)
+	assert.Len(t, drafts, 1)
+}
+
+type Location struct {
+	City   string
+	Street string
+}
+
+type Order struct {
+	ID              int64
+	BillingAddress  Location
+	ShippingAddress Location `orm:"prefix:shipping_"`
+}
+
+func (o Order) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("orders").Connection("default")
+	e.Embedded("BillingAddress").Prefix("billing_")
+}
+
+func TestEmbeddedPrefix(t *testing.T) {
+	assert.NoError(t, setup())
+	_, err := orm.GetConnection("default").DB.Exec(`CREATE TABLE orders (id INTEGER PRIMARY KEY, billing_city text, billing_street text, shipping_city text, shipping_street text)`)
+	assert.NoError(t, err)
+
+	order := &Order{
+		BillingAddress:  Location{City: "Tehran", Street: "Azadi"},
+		ShippingAddress: Location{City: "Shiraz", Street: "Zand"},
+	}
+	assert.NoError(t, orm.Insert(order))
+
+	order.ShippingAddress.Street = "Hafez"
+	assert.NoError(t, orm.Update(order))
+
+	var billingCity, shippingStreet string
+	assert.NoError(t, orm.GetConnection("default").DB.
+		QueryRow(`SELECT billing_city, shipping_street FROM orders WHERE id = ?`, order.ID).Scan(&billingCity, &shippingStreet))
+	assert.Equal(t, "Tehran", billingCity)
+	assert.Equal(t, "Hafez", shippingStreet)
+
+	found, err := orm.Query[Order]().Where("billing_city", "Tehran").Get()
+	assert.NoError(t, err)
+	assert.Equal(t, Location{City: "Tehran", Street: "Azadi"}, found.BillingAddress)
+	assert.Equal(t, Location{City: "Shiraz", Street: "Hafez"}, found.ShippingAddress)
+}
+
+type Plan struct {
+	Name  string
+	Seats int
+}
+
+type Subscription struct {
+	ID       int64
+	Plan     Plan `orm:"json"`
+	Features []string
+	Meta     map[string]string `orm:"json"`
+}
+
+func (s Subscription) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("subscriptions").Connection("default")
+	e.Field("Features").IsJSON()
+}
+
+func TestJSONColumns(t *testing.T) {
+	assert.NoError(t, setup())
+	_, err := orm.GetConnection("default").DB.Exec(`CREATE TABLE subscriptions (id INTEGER PRIMARY KEY, plan text, features text, meta text)`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, orm.Insert(&Subscription{Plan: Plan{Name: "pro", Seats: 5}, Features: []string{"sso"}}))
+	assert.NoError(t, orm.Insert(&Subscription{Plan: Plan{Name: "free", Seats: 1}, Meta: map[string]string{"source": "ads"}}))
+
+	var plan string
+	var meta sql.NullString
+	assert.NoError(t, orm.GetConnection("default").DB.QueryRow(`SELECT plan, meta FROM subscriptions WHERE id = 1`).Scan(&plan, &meta))
+	assert.JSONEq(t, `{"Name":"pro","Seats":5}`, plan)
+	assert.False(t, meta.Valid)
+
+	s, err := orm.Find[Subscription](1)
+	assert.NoError(t, err)
+	assert.Equal(t, Subscription{ID: 1, Plan: Plan{Name: "pro", Seats: 5}, Features: []string{"sso"}}, s)
+
+	ss, err := orm.Query[Subscription]().Where("id", ">", 1).All()
+	assert.NoError(t, err)
+	assert.Len(t, ss, 1)
+	assert.Equal(t, map[string]string{"source": "ads"}, ss[0].Meta)
+	assert.Nil(t, ss[0].Features)
+
+	ss, err = orm.Query[Subscription]().WhereJSON("plan->Name", "pro").All()
+	assert.NoError(t, err)
+	assert.Len(t, ss, 1)
+	assert.EqualValues(t, 1, ss[0].ID)
+
+	ss, err = orm.Query[Subscription]().WhereJSON("plan->Seats", "<", 3).OrWhereJSON("meta->source", "ads").All()
+	assert.NoError(t, err)
+	assert.Len(t, ss, 1)
+	assert.EqualValues(t, 2, ss[0].ID)
+
+	ss, err = orm.Query[Subscription]().WhereJSON("plan->Name", orm.In, "pro", "free").All()
+	assert.NoError(t, err)
+	assert.Len(t, ss, 2)
+}
+
+func TestTransaction(t *testing.T) {
+	claim := func(ctx context.Context) error {
+		post, err := orm.Query[Post]().WithContext(ctx).Where("id", 1).ForUpdate().SkipLocked().Get()
+		if err != nil {
+			return err
+		}
+		_, err = orm.Query[Post]().WithContext(ctx).Where("id", post.ID).Set("body", "claimed").Update()
+		return err
+	}
+	t.Run("commit", func(t *testing.T) {
+		assert.NoError(t, setup())
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+
+		err := orm.Transaction(context.Background(), "default", claim)
+		assert.NoError(t, err)
+
+		post, err := orm.Find[Post](1)
+		assert.NoError(t, err)
+		assert.Equal(t, "claimed", post.BodyText)
+	})
+	t.Run("rollback", func(t *testing.T) {
+		assert.NoError(t, setup())
+		assert.NoError(t, orm.Save(&Post{BodyText: "body 1"}))
+
+		errRollback := errors.New("rollback")
+		err := orm.Transaction(context.Background(), "default", func(ctx context.Context) error {
+			if err := claim(ctx); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		post, err := orm.Find[Post](1)
+		assert.NoError(t, err)
+		assert.Equal(t, "body 1", post.BodyText)
+	})
+	t.Run("context variants of crud functions join transaction", func(t *testing.T) {
+		assert.NoError(t, setup())
+		// a single connection deadlocks if a query runs outside the transaction.
+		orm.GetConnection("default").DB.SetMaxOpenConns(1)
+
+		errRollback := errors.New("rollback")
+		err := orm.Transaction(context.Background(), "default", func(ctx context.Context) error {
+			post := &Post{BodyText: "body 1"}
+			if err := orm.InsertContext(ctx, post); err != nil {
+				return err
+			}
+			if err := orm.AddContext(ctx, post, &Comment{Body: "comment 1"}); err != nil {
+				return err
+			}
+			post.BodyText = "changed"
+			if err := orm.SaveContext(ctx, post); err != nil {
+				return err
+			}
+			found, err := orm.FindContext[Post](ctx, post.ID)
+			if err != nil {
+				return err
+			}
+			assert.Equal(t, "changed", found.BodyText)
+			comments, err := orm.QueryRawContext[Comment](ctx, "SELECT * FROM comments")
+			if err != nil {
+				return err
+			}
+			assert.Len(t, comments, 1)
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		count, err := orm.Query[Post]().Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, count)
+		count, err = orm.Query[Comment]().Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, count)
+	})
+	t.Run("unknown connection", func(t *testing.T) {
+		err := orm.Transaction(context.Background(), "unknown", claim)
+		assert.ErrorIs(t, err, orm.ErrNoConnection)
+	})
+}
+
+func TestErrors(t *testing.T) {
+	t.Run("find not existing record", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		_, err = orm.Find[Post](1)
+		assert.ErrorIs(t, err, orm.ErrNotFound)
+	})
+
+	t.Run("get not existing record", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		_, err = orm.Query[Post]().WherePK(1).Get()
+		assert.ErrorIs(t, err, orm.ErrNotFound)
+
+		posts, err := orm.Query[Post]().All()
+		assert.NoError(t, err)
+		assert.Empty(t, posts)
+	})
+
+	t.Run("unique violation", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		assert.NoError(t, orm.Insert(&Post{ID: 1, BodyText: "body 1"}))
+		_, _, err = orm.ExecRaw[Post](`INSERT INTO posts (id, body) VALUES (?, ?)`, 1, "body 2")
+		assert.ErrorIs(t, err, orm.ErrUniqueViolation)
+	})
+
+	t.Run("foreign key violation on delete", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+		db := orm.GetConnection("default").DB
+		// foreign keys are enabled per sqlite connection.
+		db.SetMaxOpenConns(1)
+		_, err = db.Exec(`PRAGMA foreign_keys = ON`)
+		assert.NoError(t, err)
+		_, err = db.Exec(`CREATE TABLE post_locks (post_id INTEGER REFERENCES posts(id))`)
+		assert.NoError(t, err)
+		assert.NoError(t, orm.Insert(&Post{BodyText: "body"}))
+		_, err = db.Exec(`INSERT INTO post_locks (post_id) VALUES (1)`)
+		assert.NoError(t, err)
+
+		_, err = orm.Query[Post]().Where("id", 1).Delete()
+		assert.ErrorIs(t, err, orm.ErrForeignKeyViolation)
+	})
+
+	t.Run("relation not configured", func(t *testing.T) {
+		err := setup()
+		assert.NoError(t, err)
+
+		_, err = orm.HasMany[Category](&Comment{ID: 1}).All()
+		assert.ErrorIs(t, err, orm.ErrRelationNotConfigured)
+
+		err = orm.Add(&Comment{ID: 1}, &Category{Title: "category"})
+		assert.ErrorIs(t, err, orm.ErrRelationNotConfigured)
+	})
 }
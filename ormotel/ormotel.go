@@ -0,0 +1,113 @@
+// Package ormotel creates OpenTelemetry spans for queries that GoLobby ORM runs.
+//
+// Register its interceptor on a connection and pass context of your request to
+// query builder using WithContext, so query spans become children of your request span.
+//
+//	orm.GetConnection("default").AddInterceptor(ormotel.NewInterceptor())
+//	posts, err := orm.Query[Post]().WithContext(ctx).All()
+package ormotel
+
+import (
+	"context"
+
+	"github.com/golobby/orm"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/golobby/orm/ormotel"
+
+// Option configures Interceptor.
+type Option func(i *Interceptor)
+
+// WithTracerProvider sets tracer provider that Interceptor uses, global tracer provider
+// is used by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(i *Interceptor) {
+		i.tracerProvider = tp
+	}
+}
+
+// WithAttributes adds given attributes to all spans.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(i *Interceptor) {
+		i.attrs = append(i.attrs, attrs...)
+	}
+}
+
+// Interceptor is an orm.Interceptor that creates a client span for each query.
+type Interceptor struct {
+	tracerProvider trace.TracerProvider
+	tracer         trace.Tracer
+	attrs          []attribute.KeyValue
+}
+
+// NewInterceptor creates a new Interceptor.
+func NewInterceptor(opts ...Option) *Interceptor {
+	i := &Interceptor{}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if i.tracerProvider == nil {
+		i.tracerProvider = otel.GetTracerProvider()
+	}
+	i.tracer = i.tracerProvider.Tracer(instrumentationName)
+	return i
+}
+
+type spanKey struct{}
+
+func (i *Interceptor) BeforeQuery(ctx context.Context, event *orm.QueryEvent) context.Context {
+	attrs := append([]attribute.KeyValue{
+		attribute.String("db.system", dbSystem(event.Driver)),
+		attribute.String("db.statement", event.Query),
+		attribute.String("db.operation", event.Operation),
+	}, i.attrs...)
+	if event.Table != "" {
+		attrs = append(attrs, attribute.String("db.sql.table", event.Table))
+	}
+	ctx, span := i.tracer.Start(ctx, spanName(event),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func (i *Interceptor) AfterQuery(ctx context.Context, event *orm.QueryEvent) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	if event.RowsAffected >= 0 {
+		span.SetAttributes(attribute.Int64("db.rows_affected", event.RowsAffected))
+	}
+	if event.Err != nil {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+	span.End()
+}
+
+func spanName(event *orm.QueryEvent) string {
+	if event.Operation == "" {
+		return "query"
+	}
+	if event.Table == "" {
+		return event.Operation
+	}
+	return event.Operation + " " + event.Table
+}
+
+// dbSystem maps driver names to OpenTelemetry db.system values.
+func dbSystem(driver string) string {
+	switch driver {
+	case "postgres":
+		return "postgresql"
+	case "sqlite3":
+		return "sqlite"
+	default:
+		return driver
+	}
+}
@@ -0,0 +1,104 @@
+package ormotel_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golobby/orm"
+	"github.com/golobby/orm/ormotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type Post struct {
+	ID   int64
+	Body string
+}
+
+func (p Post) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("posts")
+}
+
+func setup(t *testing.T) *tracetest.InMemoryExporter {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS posts (id INTEGER PRIMARY KEY, body text)`)
+	assert.NoError(t, err)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	err = orm.SetupConnections(orm.ConnectionConfig{
+		Name:         "default",
+		DB:           db,
+		Dialect:      orm.Dialects.SQLite3,
+		Entities:     []orm.Entity{&Post{}},
+		Interceptors: []orm.Interceptor{ormotel.NewInterceptor(ormotel.WithTracerProvider(tp))},
+	})
+	assert.NoError(t, err)
+	return exporter
+}
+
+func attributesOf(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	m := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestInterceptor(t *testing.T) {
+	t.Run("span for each query", func(t *testing.T) {
+		exporter := setup(t)
+
+		assert.NoError(t, orm.Insert(&Post{Body: "body"}))
+		_, err := orm.Query[Post]().Where("body", "body").All()
+		assert.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 2)
+
+		assert.Equal(t, "INSERT posts", spans[0].Name)
+		attrs := attributesOf(spans[0])
+		assert.Equal(t, "sqlite", attrs["db.system"].AsString())
+		assert.Equal(t, "posts", attrs["db.sql.table"].AsString())
+		assert.Equal(t, "INSERT", attrs["db.operation"].AsString())
+		assert.Contains(t, attrs["db.statement"].AsString(), "INSERT INTO posts")
+		assert.EqualValues(t, 1, attrs["db.rows_affected"].AsInt64())
+
+		assert.Equal(t, "SELECT posts", spans[1].Name)
+		assert.Equal(t, "SELECT * FROM posts WHERE body = ?", attributesOf(spans[1])["db.statement"].AsString())
+	})
+
+	t.Run("parent span from context", func(t *testing.T) {
+		exporter := setup(t)
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+		_, err := orm.Query[Post]().WithContext(ctx).All()
+		assert.NoError(t, err)
+		parent.End()
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext.TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	})
+
+	t.Run("failed query", func(t *testing.T) {
+		exporter := setup(t)
+
+		_, _, err := orm.ExecRaw[Post](`INSERT INTO not_existing (id) VALUES (1)`)
+		assert.Error(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "INSERT", spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Len(t, spans[0].Events, 1)
+	})
+}
@@ -0,0 +1,117 @@
+// Package ormprom exposes GoLobby ORM query and connection pool metrics to Prometheus.
+//
+//	metrics := ormprom.NewMetrics()
+//	prometheus.MustRegister(metrics, ormprom.NewDBStatsCollector())
+//	orm.SetupConnections(orm.ConnectionConfig{..., Metrics: metrics})
+package ormprom
+
+import (
+	"time"
+
+	"github.com/golobby/orm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "golobby_orm"
+
+// Metrics is an orm.Metrics that counts queries and observes their latency, it's also
+// a prometheus.Collector so you need to register it.
+type Metrics struct {
+	queries  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewMetrics creates Metrics, you can pass histogram buckets for query durations,
+// prometheus.DefBuckets is used by default.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	return &Metrics{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "queries_total",
+			Help:      "Number of queries run by connection, table, operation and status.",
+		}, []string{"connection", "table", "operation", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "query_duration_seconds",
+			Help:      "Latency of queries by connection, table and operation.",
+			Buckets:   buckets,
+		}, []string{"connection", "table", "operation"}),
+	}
+}
+
+func (m *Metrics) ObserveQuery(connection string, table string, operation string, duration time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	m.queries.WithLabelValues(connection, table, operation, status).Inc()
+	m.duration.WithLabelValues(connection, table, operation).Observe(duration.Seconds())
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.queries.Describe(ch)
+	m.duration.Describe(ch)
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.queries.Collect(ch)
+	m.duration.Collect(ch)
+}
+
+var (
+	maxOpenDesc = prometheus.NewDesc(namespace+"_db_max_open_connections",
+		"Maximum number of open connections to the database.", []string{"connection"}, nil)
+	openDesc = prometheus.NewDesc(namespace+"_db_open_connections",
+		"Number of established connections both in use and idle.", []string{"connection"}, nil)
+	inUseDesc = prometheus.NewDesc(namespace+"_db_in_use_connections",
+		"Number of connections currently in use.", []string{"connection"}, nil)
+	idleDesc = prometheus.NewDesc(namespace+"_db_idle_connections",
+		"Number of idle connections.", []string{"connection"}, nil)
+	waitCountDesc = prometheus.NewDesc(namespace+"_db_wait_count_total",
+		"Total number of connections waited for.", []string{"connection"}, nil)
+	waitDurationDesc = prometheus.NewDesc(namespace+"_db_wait_duration_seconds_total",
+		"Total time blocked waiting for a new connection.", []string{"connection"}, nil)
+	maxIdleClosedDesc = prometheus.NewDesc(namespace+"_db_max_idle_closed_total",
+		"Total number of connections closed due to SetMaxIdleConns.", []string{"connection"}, nil)
+	maxIdleTimeClosedDesc = prometheus.NewDesc(namespace+"_db_max_idle_time_closed_total",
+		"Total number of connections closed due to SetConnMaxIdleTime.", []string{"connection"}, nil)
+	maxLifetimeClosedDesc = prometheus.NewDesc(namespace+"_db_max_lifetime_closed_total",
+		"Total number of connections closed due to SetConnMaxLifetime.", []string{"connection"}, nil)
+)
+
+// DBStatsCollector is a prometheus.Collector for sql.DBStats of all ORM connections.
+type DBStatsCollector struct{}
+
+// NewDBStatsCollector creates a DBStatsCollector.
+func NewDBStatsCollector() *DBStatsCollector {
+	return &DBStatsCollector{}
+}
+
+func (c *DBStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- maxOpenDesc
+	ch <- openDesc
+	ch <- inUseDesc
+	ch <- idleDesc
+	ch <- waitCountDesc
+	ch <- waitDurationDesc
+	ch <- maxIdleClosedDesc
+	ch <- maxIdleTimeClosedDesc
+	ch <- maxLifetimeClosedDesc
+}
+
+func (c *DBStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, stats := range orm.DBStats() {
+		ch <- prometheus.MustNewConstMetric(maxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(openDesc, prometheus.GaugeValue, float64(stats.OpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(inUseDesc, prometheus.GaugeValue, float64(stats.InUse), name)
+		ch <- prometheus.MustNewConstMetric(idleDesc, prometheus.GaugeValue, float64(stats.Idle), name)
+		ch <- prometheus.MustNewConstMetric(waitCountDesc, prometheus.CounterValue, float64(stats.WaitCount), name)
+		ch <- prometheus.MustNewConstMetric(waitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(maxIdleClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed), name)
+		ch <- prometheus.MustNewConstMetric(maxIdleTimeClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), name)
+		ch <- prometheus.MustNewConstMetric(maxLifetimeClosedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), name)
+	}
+}
@@ -0,0 +1,71 @@
+package ormprom_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/golobby/orm"
+	"github.com/golobby/orm/ormprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type Post struct {
+	ID   int64
+	Body string
+}
+
+func (p Post) ConfigureEntity(e *orm.EntityConfigurator) {
+	e.Table("posts")
+}
+
+func setup(t *testing.T, metrics orm.Metrics) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS posts (id INTEGER PRIMARY KEY, body text)`)
+	assert.NoError(t, err)
+
+	err = orm.SetupConnections(orm.ConnectionConfig{
+		Name:     "default",
+		DB:       db,
+		Dialect:  orm.Dialects.SQLite3,
+		Entities: []orm.Entity{&Post{}},
+		Metrics:  metrics,
+	})
+	assert.NoError(t, err)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := ormprom.NewMetrics()
+	setup(t, metrics)
+
+	assert.NoError(t, orm.Insert(&Post{Body: "body 1"}))
+	assert.NoError(t, orm.Insert(&Post{Body: "body 2"}))
+	_, err := orm.Query[Post]().All()
+	assert.NoError(t, err)
+	_, _, err = orm.ExecRaw[Post](`DELETE FROM not_existing`)
+	assert.Error(t, err)
+
+	expected := `
+# HELP golobby_orm_queries_total Number of queries run by connection, table, operation and status.
+# TYPE golobby_orm_queries_total counter
+golobby_orm_queries_total{connection="default",operation="delete",status="error",table=""} 1
+golobby_orm_queries_total{connection="default",operation="insert",status="ok",table="posts"} 2
+golobby_orm_queries_total{connection="default",operation="select",status="ok",table="posts"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(metrics, strings.NewReader(expected), "golobby_orm_queries_total"))
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics, "golobby_orm_query_duration_seconds"))
+}
+
+func TestDBStatsCollector(t *testing.T) {
+	setup(t, nil)
+
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(ormprom.NewDBStatsCollector()))
+
+	count, err := testutil.GatherAndCount(registry, "golobby_orm_db_open_connections", "golobby_orm_db_max_open_connections")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,360 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CursorPage is a page of results returned from CursorPaginate.
+type CursorPage[E any] struct {
+	Items []E
+	// NextCursor fetches page after this one, it's empty when there is no next page.
+	NextCursor string
+	// PrevCursor fetches page before this one, it's empty when there is no previous page.
+	PrevCursor string
+}
+
+type cursorDirection string
+
+const (
+	cursorNext cursorDirection = "next"
+	cursorPrev cursorDirection = "prev"
+)
+
+// cursorValue is a value of an ordered column kept with its type, so it's sent back to
+// database with same type it was read.
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+type cursor struct {
+	Direction cursorDirection `json:"d"`
+	Values    []cursorValue   `json:"v"`
+}
+
+type orderColumn struct {
+	name string
+	how  string
+}
+
+// CursorPaginate is a finisher, it fetches pageSize items ordered by orderColumns that come after or before
+// the row given cursor was created from, an empty cursor fetches first page. orderColumns are column names
+// optionally followed by ASC or DESC like "created_at DESC", primary key is added as last order column
+// to break ties. Unlike Offset it does not get slower on later pages, but ordered columns should not be NULL.
+func (q *QueryBuilder[OUTPUT]) CursorPaginate(cursorStr string, pageSize int, orderColumns ...string) (*CursorPage[OUTPUT], error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size should be positive, got %d", pageSize)
+	}
+	if q.orderBy != nil || q.limit != nil || q.offset != nil {
+		return nil, fmt.Errorf("CursorPaginate sets order, limit and offset of query itself")
+	}
+	columns, err := q.cursorOrderColumns(orderColumns)
+	if err != nil {
+		return nil, err
+	}
+	c := cursor{Direction: cursorNext}
+	if cursorStr != "" {
+		c, err = decodeCursor(cursorStr, len(columns))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pq := NewQueryBuilder[OUTPUT](q.schema)
+	copyQueryBuilder(q, pq)
+	pq.SetSelect()
+	for _, column := range columns {
+		how := column.how
+		if c.Direction == cursorPrev {
+			how = reverseOrder(how)
+		}
+		pq.OrderBy(column.name, how)
+	}
+	pq.Limit(pageSize + 1)
+	if cursorStr != "" {
+		pq.where = groupWhereClause(pq.where)
+		pq.Where(keysetCondition(columns, c))
+	}
+	items, err := pq.All()
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(items) > pageSize
+	if hasMore {
+		items = items[:pageSize]
+	}
+	if c.Direction == cursorPrev {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	page := &CursorPage[OUTPUT]{Items: items}
+	if len(items) == 0 {
+		return page, nil
+	}
+	hasNext := (c.Direction == cursorNext && hasMore) || (c.Direction == cursorPrev && cursorStr != "")
+	hasPrev := (c.Direction == cursorPrev && hasMore) || (c.Direction == cursorNext && cursorStr != "")
+	if hasNext {
+		page.NextCursor, err = q.encodeCursor(cursorNext, columns, &items[len(items)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		page.PrevCursor, err = q.encodeCursor(cursorPrev, columns, &items[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// cursorOrderColumns parses order columns and adds primary key to them if it's not already there.
+func (q *QueryBuilder[OUTPUT]) cursorOrderColumns(orderColumns []string) ([]orderColumn, error) {
+	var columns []orderColumn
+	var hasPK bool
+	pk := ""
+	if q.schema != nil {
+		pk = q.schema.pkName()
+	}
+	for _, oc := range orderColumns {
+		parts := strings.Fields(oc)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("invalid order column %q", oc)
+		}
+		column := orderColumn{name: parts[0], how: ASC}
+		if len(parts) == 2 {
+			column.how = strings.ToUpper(parts[1])
+			if column.how != ASC && column.how != DESC {
+				return nil, fmt.Errorf("invalid order direction %q", parts[1])
+			}
+		}
+		if pk != "" && unqualifiedColumn(column.name) == pk {
+			hasPK = true
+		}
+		columns = append(columns, column)
+	}
+	if !hasPK && pk != "" {
+		columns = append(columns, orderColumn{name: pk, how: ASC})
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("CursorPaginate needs order columns when entity has no primary key")
+	}
+	return columns, nil
+}
+
+// keysetCondition creates condition of rows that come after cursor values in columns order, like
+// (a > ? OR (a = ? AND b > ?)), comparisons are reversed for DESC columns and previous pages.
+func keysetCondition(columns []orderColumn, c cursor) *raw {
+	var ors []string
+	var args []interface{}
+	for i, column := range columns {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, columns[j].name+" = ?")
+			args = append(args, c.Values[j].value())
+		}
+		op := GT
+		if column.how == DESC {
+			op = LT
+		}
+		if c.Direction == cursorPrev {
+			if op == GT {
+				op = LT
+			} else {
+				op = GT
+			}
+		}
+		ands = append(ands, fmt.Sprintf("%s %s ?", column.name, op))
+		args = append(args, c.Values[i].value())
+		if len(ands) == 1 {
+			ors = append(ors, ands[0])
+		} else {
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+	}
+	return Raw("("+strings.Join(ors, " OR ")+")", args...)
+}
+
+func reverseOrder(how string) string {
+	if how == DESC {
+		return ASC
+	}
+	return DESC
+}
+
+func unqualifiedColumn(column string) string {
+	return column[strings.LastIndex(column, ".")+1:]
+}
+
+func (q *QueryBuilder[OUTPUT]) encodeCursor(direction cursorDirection, columns []orderColumn, item *OUTPUT) (string, error) {
+	ptrs, isMap := newBinderFor[OUTPUT](q.schema).makeNewPointersOf(reflect.ValueOf(item).Elem()).(map[string]interface{})
+	if !isMap {
+		return "", fmt.Errorf("CursorPaginate needs a struct to read cursor values from, got %T", *item)
+	}
+	c := cursor{Direction: direction}
+	for _, column := range columns {
+		ptr, exists := ptrs[unqualifiedColumn(column.name)]
+		if !exists {
+			return "", fmt.Errorf("order column %s is not a field of %T", column.name, *item)
+		}
+		v, err := driver.DefaultParameterConverter.ConvertValue(reflect.ValueOf(ptr).Elem().Interface())
+		if err != nil {
+			return "", err
+		}
+		cv, err := newCursorValue(v)
+		if err != nil {
+			return "", fmt.Errorf("order column %s: %w", column.name, err)
+		}
+		c.Values = append(c.Values, cv)
+	}
+	bs, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+func decodeCursor(s string, columns int) (cursor, error) {
+	var c cursor
+	bs, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+	if err = json.Unmarshal(bs, &c); err != nil {
+		return c, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+	if c.Direction != cursorNext && c.Direction != cursorPrev {
+		return c, fmt.Errorf("%w: unknown direction %q", ErrInvalidCursor, c.Direction)
+	}
+	if len(c.Values) != columns {
+		return c, fmt.Errorf("%w: cursor has %d values for %d order columns", ErrInvalidCursor, len(c.Values), columns)
+	}
+	for _, v := range c.Values {
+		if err = v.validate(); err != nil {
+			return c, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+		}
+	}
+	return c, nil
+}
+
+func newCursorValue(v driver.Value) (cursorValue, error) {
+	switch v := v.(type) {
+	case int64:
+		return cursorValue{Type: "int", Value: strconv.FormatInt(v, 10)}, nil
+	case float64:
+		return cursorValue{Type: "float", Value: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+	case bool:
+		return cursorValue{Type: "bool", Value: strconv.FormatBool(v)}, nil
+	case string:
+		return cursorValue{Type: "string", Value: v}, nil
+	case []byte:
+		return cursorValue{Type: "bytes", Value: base64.StdEncoding.EncodeToString(v)}, nil
+	case time.Time:
+		return cursorValue{Type: "time", Value: v.Format(time.RFC3339Nano)}, nil
+	case nil:
+		return cursorValue{}, fmt.Errorf("cannot paginate on NULL values")
+	default:
+		return cursorValue{}, fmt.Errorf("unsupported cursor value type %T", v)
+	}
+}
+
+func (c cursorValue) parse() (interface{}, error) {
+	switch c.Type {
+	case "int":
+		return strconv.ParseInt(c.Value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(c.Value, 64)
+	case "bool":
+		return strconv.ParseBool(c.Value)
+	case "string":
+		return c.Value, nil
+	case "bytes":
+		return base64.StdEncoding.DecodeString(c.Value)
+	case "time":
+		return time.Parse(time.RFC3339Nano, c.Value)
+	default:
+		return nil, fmt.Errorf("unknown cursor value type %q", c.Type)
+	}
+}
+
+func (c cursorValue) validate() error {
+	_, err := c.parse()
+	return err
+}
+
+// value returns parsed value of a cursorValue that is already validated.
+func (c cursorValue) value() interface{} {
+	v, _ := c.parse()
+	return v
+}
+
+// Page is a page of results returned from Paginate.
+type Page[E any] struct {
+	Items []E
+	// Total is number of all rows query matches.
+	Total    int64
+	Page     int
+	PerPage  int
+	LastPage int
+}
+
+// Paginate is a finisher, it fetches perPage items of given page, pages start from 1, and counts
+// all rows query matches using same query without order, limit and offset.
+func (q *QueryBuilder[OUTPUT]) Paginate(page int, perPage int) (*Page[OUTPUT], error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if page < 1 {
+		return nil, fmt.Errorf("page should be 1 or more, got %d", page)
+	}
+	if perPage <= 0 {
+		return nil, fmt.Errorf("per page should be positive, got %d", perPage)
+	}
+	if q.limit != nil || q.offset != nil {
+		return nil, fmt.Errorf("Paginate sets limit and offset of query itself")
+	}
+	total, err := q.countRows()
+	if err != nil {
+		return nil, err
+	}
+	pq := NewQueryBuilder[OUTPUT](q.schema)
+	copyQueryBuilder(q, pq)
+	items, err := pq.Limit(perPage).Offset((page - 1) * perPage).All()
+	if err != nil {
+		return nil, err
+	}
+	lastPage := int((total + int64(perPage) - 1) / int64(perPage))
+	if lastPage < 1 {
+		lastPage = 1
+	}
+	return &Page[OUTPUT]{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PerPage:  perPage,
+		LastPage: lastPage,
+	}, nil
+}
+
+// countRows counts all rows query matches regardless of its order, limit and offset, grouped
+// and distinct queries are counted by aggregate so each group or distinct row counts once.
+func (q *QueryBuilder[OUTPUT]) countRows() (int64, error) {
+	cq := NewQueryBuilder[OUTPUT](q.schema)
+	copyQueryBuilder(q, cq)
+	cq.orderBy = nil
+	cq.limit = nil
+	cq.offset = nil
+	return aggregate[int64](cq.SetSelect(), "COUNT(*)")
+}
@@ -0,0 +1,155 @@
+package orm_test
+
+import (
+	"testing"
+
+	"github.com/golobby/orm"
+	"github.com/stretchr/testify/assert"
+)
+
+func postIDs(posts []Post) []int64 {
+	var ids []int64
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestCursorPaginate(t *testing.T) {
+	assert.NoError(t, setup())
+	for _, body := range []string{"a", "a", "b", "b", "c"} {
+		assert.NoError(t, orm.Save(&Post{BodyText: body}))
+	}
+
+	t.Run("primary key", func(t *testing.T) {
+		page, err := orm.Query[Post]().CursorPaginate("", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, postIDs(page.Items))
+		assert.Empty(t, page.PrevCursor)
+		assert.NotEmpty(t, page.NextCursor)
+
+		page, err = orm.Query[Post]().CursorPaginate(page.NextCursor, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{3, 4}, postIDs(page.Items))
+
+		page, err = orm.Query[Post]().CursorPaginate(page.NextCursor, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{5}, postIDs(page.Items))
+		assert.Empty(t, page.NextCursor)
+
+		page, err = orm.Query[Post]().CursorPaginate(page.PrevCursor, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{3, 4}, postIDs(page.Items))
+		assert.NotEmpty(t, page.NextCursor)
+
+		page, err = orm.Query[Post]().CursorPaginate(page.PrevCursor, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, postIDs(page.Items))
+		assert.Empty(t, page.PrevCursor)
+	})
+
+	t.Run("multi column with tie breaking", func(t *testing.T) {
+		page, err := orm.Query[Post]().Where("id", "!=", 0).CursorPaginate("", 2, "body DESC")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{5, 3}, postIDs(page.Items))
+
+		page, err = orm.Query[Post]().Where("id", "!=", 0).CursorPaginate(page.NextCursor, 2, "body DESC")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{4, 1}, postIDs(page.Items))
+
+		next, err := orm.Query[Post]().Where("id", "!=", 0).CursorPaginate(page.NextCursor, 2, "body DESC")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{2}, postIDs(next.Items))
+
+		prev, err := orm.Query[Post]().Where("id", "!=", 0).CursorPaginate(page.PrevCursor, 2, "body DESC")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{5, 3}, postIDs(prev.Items))
+	})
+
+	t.Run("or conditions do not skip keyset condition", func(t *testing.T) {
+		query := func() *orm.QueryBuilder[Post] {
+			return orm.Query[Post]().Where("body", "a").OrWhere("body", "b")
+		}
+		page, err := query().CursorPaginate("", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, postIDs(page.Items))
+
+		page, err = query().CursorPaginate(page.NextCursor, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{3, 4}, postIDs(page.Items))
+		assert.Empty(t, page.NextCursor)
+
+		var chunks [][]int64
+		err = query().Chunk(3, func(posts []Post) error {
+			chunks = append(chunks, postIDs(posts))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, [][]int64{{1, 2, 3}, {4}}, chunks)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := orm.Query[Post]().CursorPaginate("not a cursor", 2)
+		assert.ErrorIs(t, err, orm.ErrInvalidCursor)
+
+		page, err := orm.Query[Post]().CursorPaginate("", 2)
+		assert.NoError(t, err)
+		_, err = orm.Query[Post]().CursorPaginate(page.NextCursor, 2, "body")
+		assert.ErrorIs(t, err, orm.ErrInvalidCursor)
+	})
+
+	t.Run("ordered query", func(t *testing.T) {
+		_, err := orm.Query[Post]().OrderBy("id", orm.DESC).CursorPaginate("", 2)
+		assert.Error(t, err)
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	assert.NoError(t, setup())
+	for _, body := range []string{"a", "a", "b", "b", "c"} {
+		assert.NoError(t, orm.Save(&Post{BodyText: body}))
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		q := orm.Query[Post]().Where("id", ">", 0).OrderBy("id", orm.DESC)
+		page, err := q.Paginate(1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{5, 4}, postIDs(page.Items))
+		assert.EqualValues(t, 5, page.Total)
+		assert.Equal(t, 1, page.Page)
+		assert.Equal(t, 2, page.PerPage)
+		assert.Equal(t, 3, page.LastPage)
+
+		page, err = q.Paginate(3, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1}, postIDs(page.Items))
+
+		page, err = q.Paginate(4, 2)
+		assert.NoError(t, err)
+		assert.Empty(t, page.Items)
+		assert.EqualValues(t, 5, page.Total)
+	})
+
+	t.Run("distinct", func(t *testing.T) {
+		page, err := orm.Query[Post]().Select("body").Distinct().OrderBy("body", orm.ASC).Paginate(1, 2)
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		assert.EqualValues(t, 3, page.Total)
+		assert.Equal(t, 2, page.LastPage)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		page, err := orm.Query[Post]().Where("id", 0).Paginate(1, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, page.Items)
+		assert.EqualValues(t, 0, page.Total)
+		assert.Equal(t, 1, page.LastPage)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := orm.Query[Post]().Paginate(0, 10)
+		assert.Error(t, err)
+		_, err = orm.Query[Post]().Limit(1).Paginate(1, 10)
+		assert.Error(t, err)
+	})
+}
@@ -1,8 +1,11 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -21,25 +24,31 @@ type QueryBuilder[OUTPUT any] struct {
 	where                *whereClause
 	table                string
 	placeholderGenerator func(n int) []string
+	dialect              *Dialect
 
 	// select parts
 	orderBy  *orderByClause
 	groupBy  *GroupBy
+	having   *whereClause
+	windows  []window
+	distinct bool
 	selected *selected
-	subQuery *struct {
-		q                    string
-		args                 []interface{}
-		placeholderGenerator func(n int) []string
-	}
-	joins  []*Join
-	limit  *Limit
-	offset *Offset
+	ctes     []cte
+	subQuery SubQuery
+	// alias is name of the table or derived table when selecting from subQuery.
+	alias     string
+	compounds []compound
+	joins     []*Join
+	limit     *Limit
+	offset    *Offset
+	lock      *lock
 
 	// update parts
 	sets [][2]interface{}
 
 	// execution parts
 	db  *sql.DB
+	ctx context.Context
 	err error
 }
 
@@ -58,24 +67,38 @@ func (q *QueryBuilder[OUTPUT]) execute() (sql.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	return q.schema.getConnection().exec(query, args...)
+	conn, err := q.schema.getConnection()
+	if err != nil {
+		return nil, err
+	}
+	return conn.exec(q.context(), q.table, query, args...)
 }
 
-// Get limit results to 1, runs query generated by query builder, scans result into OUTPUT.
-func (q *QueryBuilder[OUTPUT]) Get() (OUTPUT, error) {
+// queryRows runs select query generated by QueryBuilder and returns its rows.
+func (q *QueryBuilder[OUTPUT]) queryRows() (*sql.Rows, error) {
 	if q.err != nil {
-		return *new(OUTPUT), q.err
+		return nil, q.err
 	}
+	q.SetSelect()
 	queryString, args, err := q.ToSql()
 	if err != nil {
-		return *new(OUTPUT), err
+		return nil, err
 	}
-	rows, err := q.schema.getConnection().query(queryString, args...)
+	conn, err := q.schema.getConnection()
+	if err != nil {
+		return nil, err
+	}
+	return conn.query(q.context(), q.table, queryString, args...)
+}
+
+// Get limit results to 1, runs query generated by query builder, scans result into OUTPUT.
+func (q *QueryBuilder[OUTPUT]) Get() (OUTPUT, error) {
+	rows, err := q.queryRows()
 	if err != nil {
 		return *new(OUTPUT), err
 	}
 	var output OUTPUT
-	err = newBinder(q.schema).bind(rows, &output)
+	err = newBinderFor[OUTPUT](q.schema).bind(rows, &output)
 	if err != nil {
 		return *new(OUTPUT), err
 	}
@@ -85,24 +108,38 @@ func (q *QueryBuilder[OUTPUT]) Get() (OUTPUT, error) {
 // All is a finisher, create the Select query based on QueryBuilder and scan results into
 // slice of type parameter E.
 func (q *QueryBuilder[OUTPUT]) All() ([]OUTPUT, error) {
-	if q.err != nil {
-		return nil, q.err
+	rows, err := q.queryRows()
+	if err != nil {
+		return nil, err
 	}
-	q.SetSelect()
-	queryString, args, err := q.ToSql()
+	var output []OUTPUT
+	err = newBinderFor[OUTPUT](q.schema).bind(rows, &output)
 	if err != nil {
 		return nil, err
 	}
-	rows, err := q.schema.getConnection().query(queryString, args...)
+	return output, nil
+}
+
+// AllMaps is a finisher, it runs select query generated by QueryBuilder and returns each row as a map
+// of column name to its value, useful when there is no struct for the result.
+func (q *QueryBuilder[OUTPUT]) AllMaps() ([]map[string]interface{}, error) {
+	rows, err := q.queryRows()
 	if err != nil {
 		return nil, err
 	}
-	var output []OUTPUT
-	err = newBinder(q.schema).bind(rows, &output)
+	return bindToMap(rows)
+}
+
+// GetMap is like AllMaps but returns only the first row, it returns ErrNotFound if there is no row.
+func (q *QueryBuilder[OUTPUT]) GetMap() (map[string]interface{}, error) {
+	ms, err := q.AllMaps()
 	if err != nil {
 		return nil, err
 	}
-	return output, nil
+	if len(ms) == 0 {
+		return nil, ErrNotFound
+	}
+	return ms[0], nil
 }
 
 // Delete is a finisher, creates a delete query from query builder and executes it.
@@ -113,7 +150,7 @@ func (q *QueryBuilder[OUTPUT]) Delete() (rowsAffected int64, err error) {
 	q.SetDelete()
 	res, err := q.execute()
 	if err != nil {
-		return 0, q.err
+		return 0, err
 	}
 	return res.RowsAffected()
 }
@@ -133,44 +170,176 @@ func (q *QueryBuilder[OUTPUT]) Update() (rowsAffected int64, err error) {
 
 func copyQueryBuilder[T1 any, T2 any](q *QueryBuilder[T1], q2 *QueryBuilder[T2]) {
 	q2.db = q.db
+	q2.ctx = q.ctx
 	q2.err = q.err
-	q2.groupBy = q.groupBy
+	q2.groupBy = q.groupBy.clone()
+	q2.distinct = q.distinct
 	q2.joins = q.joins
 	q2.limit = q.limit
 	q2.offset = q.offset
-	q2.orderBy = q.orderBy
+	q2.lock = q.lock
+	q2.orderBy = q.orderBy.clone()
 	q2.placeholderGenerator = q.placeholderGenerator
+	q2.dialect = q.dialect
+	q2.windows = q.windows
 	q2.schema = q.schema
-	q2.selected = q.selected
+	q2.selected = q.selected.clone()
 	q2.sets = q.sets
 
+	q2.ctes = q.ctes
 	q2.subQuery = q.subQuery
+	q2.alias = q.alias
+	q2.compounds = q.compounds
 	q2.table = q.table
 	q2.typ = q.typ
-	q2.where = q.where
+	// conditions are appended in place, so each builder needs its own chain.
+	q2.where = q.where.clone()
+	q2.having = q.having.clone()
 }
 
-// Count creates and execute a select query from QueryBuilder and set it's field list of selection
-// to COUNT(id).
-func (q *QueryBuilder[OUTPUT]) Count() *QueryBuilder[int] {
-	q.selected = &selected{Columns: []string{"COUNT(id)"}}
-	q.SetSelect()
-	qCount := NewQueryBuilder[int](q.schema)
+// Count is a finisher, it creates and executes a select query from QueryBuilder with
+// COUNT(primary key) as field list and returns the result, grouped, distinct, limited
+// and compound queries count rows they return, so each group or distinct row counts once.
+func (q *QueryBuilder[OUTPUT]) Count() (int64, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
+	column := "*"
+	if q.schema != nil && q.schema.pkName() != "" && !q.needsDerivedTable() {
+		column = q.schema.pkName()
+	}
+	return aggregate[int64](q, fmt.Sprintf("COUNT(%s)", column))
+}
+
+// needsDerivedTable reports whether q should be aggregated as a derived table, since its
+// grouping, distinct, limit, offset or compounds change which rows it returns.
+func (q *QueryBuilder[OUTPUT]) needsDerivedTable() bool {
+	return len(q.compounds) > 0 || q.groupBy != nil || q.having != nil || q.limit != nil || q.offset != nil || q.distinct
+}
+
+// derivedTable creates a QueryBuilder that selects from q as a derived table named alias.
+func derivedTable[T any, OUTPUT any](q *QueryBuilder[OUTPUT], alias string) *QueryBuilder[T] {
+	dq := NewQueryBuilder[T](q.schema)
+	dq.ctx = q.ctx
+	dq.dialect = q.dialect
+	dq.placeholderGenerator = q.placeholderGenerator
+	// WITH clause stays on the outer query so named subqueries are visible to all parts.
+	inner := NewQueryBuilder[OUTPUT](q.schema)
+	copyQueryBuilder(q, inner)
+	inner.ctes = nil
+	dq.ctes = q.ctes
+	dq.subQuery = inner
+	dq.alias = alias
+	return dq.SetSelect()
+}
+
+// Select creates a QueryBuilder with same state as q that binds results into DTO instead of entity of q,
+// DTO can be any struct, whose fields are matched with columns using their db tag or snake case name,
+// or a scalar type when a single column is selected.
+func Select[DTO any, E any](q *QueryBuilder[E]) *QueryBuilder[DTO] {
+	dq := NewQueryBuilder[DTO](q.schema)
+	copyQueryBuilder(q, dq)
+	return dq.SetSelect()
+}
+
+// Pluck is a finisher, it selects only given column of rows query matches and returns
+// its values as a slice of T.
+func Pluck[T any, E any](q *QueryBuilder[E], column string) ([]T, error) {
+	pq := Select[T](q)
+	pq.selected = &selected{Columns: []string{column}}
+	return pq.All()
+}
+
+// Sum is a finisher, it executes query with SUM(column) as field list and returns the result
+// as T, it returns zero value of T when there is no row.
+func Sum[T any, OUTPUT any](q *QueryBuilder[OUTPUT], column string) (T, error) {
+	return aggregate[T](q, fmt.Sprintf("SUM(%s)", column))
+}
+
+// Avg is a finisher, it executes query with AVG(column) as field list and returns the result
+// as T, it returns zero value of T when there is no row.
+func Avg[T any, OUTPUT any](q *QueryBuilder[OUTPUT], column string) (T, error) {
+	return aggregate[T](q, fmt.Sprintf("AVG(%s)", column))
+}
+
+// Min is a finisher, it executes query with MIN(column) as field list and returns the result
+// as T, it returns zero value of T when there is no row.
+func Min[T any, OUTPUT any](q *QueryBuilder[OUTPUT], column string) (T, error) {
+	return aggregate[T](q, fmt.Sprintf("MIN(%s)", column))
+}
 
-	copyQueryBuilder(q, qCount)
+// Max is a finisher, it executes query with MAX(column) as field list and returns the result
+// as T, it returns zero value of T when there is no row.
+func Max[T any, OUTPUT any](q *QueryBuilder[OUTPUT], column string) (T, error) {
+	return aggregate[T](q, fmt.Sprintf("MAX(%s)", column))
+}
 
-	return qCount
+// aggregate runs a copy of q with expr as its only selected field and scans first row into T,
+// order by and row locks are removed since they have no meaning for an aggregated result.
+// queries whose grouping, distinct, limit, offset or compounds change returned rows are
+// aggregated as a derived table, so expr is computed over rows q returns.
+func aggregate[T any, OUTPUT any](q *QueryBuilder[OUTPUT], expr string) (T, error) {
+	if q.err != nil {
+		return *new(T), q.err
+	}
+	var aq *QueryBuilder[T]
+	if q.needsDerivedTable() {
+		iq := NewQueryBuilder[OUTPUT](q.schema)
+		copyQueryBuilder(q, iq)
+		iq.lock = nil
+		if iq.limit == nil && iq.offset == nil {
+			// order only matters for which rows are limited.
+			iq.orderBy = nil
+		}
+		aq = derivedTable[T](iq.SetSelect(), "aggregated")
+	} else {
+		aq = NewQueryBuilder[T](q.schema)
+		copyQueryBuilder(q, aq)
+		aq.orderBy = nil
+		aq.lock = nil
+	}
+	aq.selected = &selected{Columns: []string{expr}}
+	aq.SetSelect()
+	query, args, err := aq.ToSql()
+	if err != nil {
+		return *new(T), err
+	}
+	conn, err := q.schema.getConnection()
+	if err != nil {
+		return *new(T), err
+	}
+	rows, err := conn.query(aq.context(), aq.table, query, args...)
+	if err != nil {
+		return *new(T), err
+	}
+	defer rows.Close()
+	var out sql.Null[T]
+	if rows.Next() {
+		if err = rows.Scan(&out); err != nil {
+			return *new(T), err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return *new(T), err
+	}
+	return out.V, nil
 }
 
 // First returns first record of database using OrderBy primary key
 // ascending order.
 func (q *QueryBuilder[OUTPUT]) First() *QueryBuilder[OUTPUT] {
+	if q.err != nil {
+		return q
+	}
 	q.OrderBy(q.schema.pkName(), ASC).Limit(1)
 	return q
 }
 
 // Latest is like Get but it also do a OrderBy(primary key, DESC)
 func (q *QueryBuilder[OUTPUT]) Latest() *QueryBuilder[OUTPUT] {
+	if q.err != nil {
+		return q
+	}
 	q.OrderBy(q.schema.pkName(), DESC).Limit(1)
 	return q
 }
@@ -178,15 +347,19 @@ func (q *QueryBuilder[OUTPUT]) Latest() *QueryBuilder[OUTPUT] {
 // WherePK adds a where clause to QueryBuilder and also gets primary key name
 // from type parameter schema.
 func (q *QueryBuilder[OUTPUT]) WherePK(value interface{}) *QueryBuilder[OUTPUT] {
+	if q.err != nil {
+		return q
+	}
 	return q.Where(q.schema.pkName(), value)
 }
 
 func (d *QueryBuilder[OUTPUT]) toSqlDelete() (string, []interface{}, error) {
+	ph := newPlaceholders(d.placeholderGenerator)
+	ph.dialect = d.dialect
 	base := fmt.Sprintf("DELETE FROM %s", d.table)
 	var args []interface{}
 	if d.where != nil {
-		d.where.PlaceHolderGenerator = d.placeholderGenerator
-		where, whereArgs, err := d.where.ToSql()
+		where, whereArgs, err := d.where.toSql(ph)
 		if err != nil {
 			return "", nil, err
 		}
@@ -195,17 +368,12 @@ func (d *QueryBuilder[OUTPUT]) toSqlDelete() (string, []interface{}, error) {
 	}
 	return base, args, nil
 }
-func pop(phs *[]string) string {
-	top := (*phs)[len(*phs)-1]
-	*phs = (*phs)[:len(*phs)-1]
-	return top
-}
 
-func (u *QueryBuilder[OUTPUT]) kvString() string {
-	phs := u.placeholderGenerator(len(u.sets))
+func (u *QueryBuilder[OUTPUT]) kvString(ph *placeholders) string {
+	phs := ph.next(len(u.sets))
 	var sets []string
-	for _, pair := range u.sets {
-		sets = append(sets, fmt.Sprintf("%s=%s", pair[0], pop(&phs)))
+	for i, pair := range u.sets {
+		sets = append(sets, fmt.Sprintf("%s=%s", pair[0], phs[i]))
 	}
 	return strings.Join(sets, ",")
 }
@@ -222,11 +390,12 @@ func (u *QueryBuilder[OUTPUT]) toSqlUpdate() (string, []interface{}, error) {
 	if u.table == "" {
 		return "", nil, fmt.Errorf("table cannot be empty")
 	}
-	base := fmt.Sprintf("UPDATE %s SET %s", u.table, u.kvString())
+	ph := newPlaceholders(u.placeholderGenerator)
+	ph.dialect = u.dialect
+	base := fmt.Sprintf("UPDATE %s SET %s", u.table, u.kvString(ph))
 	args := u.args()
 	if u.where != nil {
-		u.where.PlaceHolderGenerator = u.placeholderGenerator
-		where, whereArgs, err := u.where.ToSql()
+		where, whereArgs, err := u.where.toSql(ph)
 		if err != nil {
 			return "", nil, err
 		}
@@ -235,71 +404,280 @@ func (u *QueryBuilder[OUTPUT]) toSqlUpdate() (string, []interface{}, error) {
 	}
 	return base, args, nil
 }
+
 func (s *QueryBuilder[OUTPUT]) toSqlSelect() (string, []interface{}, error) {
+	ph := newPlaceholders(s.placeholderGenerator)
+	ph.dialect = s.dialect
+	return s.renderSelect(ph)
+}
+
+// SubQuery is a select query that can be rendered as part of another query,
+// it's implemented by QueryBuilder regardless of its OUTPUT so queries of other
+// entities can be used as subqueries too.
+type SubQuery interface {
+	renderSelect(ph *placeholders) (string, []interface{}, error)
+	validateCompoundOperand() error
+}
+
+// renderSelect validates QueryBuilder state and renders each clause of select query in the
+// order SQL expects them, all clauses share ph so placeholders are numbered correctly.
+func (s *QueryBuilder[OUTPUT]) renderSelect(ph *placeholders) (string, []interface{}, error) {
 	if s.err != nil {
 		return "", nil, s.err
 	}
-	base := "SELECT"
+	if err := s.validateSelect(); err != nil {
+		return "", nil, err
+	}
+	clauses := []func(ph *placeholders) (string, []interface{}, error){
+		s.renderWith,
+		s.renderSelectList,
+		s.renderFrom,
+		s.renderJoins,
+		s.renderWhere,
+		s.renderGroupBy,
+		s.renderHaving,
+		s.renderWindows,
+		s.renderCompounds,
+		s.renderOrderBy,
+		s.renderLimitOffset,
+		s.renderLock,
+	}
+	var parts []string
 	var args []interface{}
-	// select
-	if s.selected == nil {
-		s.selected = &selected{
-			Columns: []string{"*"},
+	for _, clause := range clauses {
+		part, clauseArgs, err := clause(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		if part == "" {
+			continue
 		}
+		parts = append(parts, part)
+		args = append(args, clauseArgs...)
 	}
-	base += " " + s.selected.String()
-	// from
+	return strings.Join(parts, " "), args, nil
+}
+
+// validateSelect returns an error if QueryBuilder has a combination of
+// parts that cannot be rendered into a valid select query.
+func (s *QueryBuilder[OUTPUT]) validateSelect() error {
 	if s.table == "" && s.subQuery == nil {
-		return "", nil, fmt.Errorf("Table name cannot be empty")
-	} else if s.table != "" && s.subQuery != nil {
-		return "", nil, fmt.Errorf("cannot have both Table and subquery")
+		return fmt.Errorf("Table name cannot be empty")
+	}
+	if s.table != "" && s.subQuery != nil {
+		return fmt.Errorf("cannot have both Table and subquery")
+	}
+	if len(s.sets) > 0 {
+		return fmt.Errorf("cannot have Set in a SELECT query")
+	}
+	if s.limit != nil && s.limit.N < 0 {
+		return fmt.Errorf("limit cannot be negative: %d", s.limit.N)
 	}
-	if s.table != "" {
-		base += " " + "FROM " + s.table
+	if s.offset != nil && s.offset.N < 0 {
+		return fmt.Errorf("offset cannot be negative: %d", s.offset.N)
 	}
+	if s.offset != nil && s.limit == nil && s.dialect != nil && s.dialect.DriverName != Dialects.PostgreSQL.DriverName {
+		return fmt.Errorf("%s does not support OFFSET without LIMIT", s.dialect.DriverName)
+	}
+	if s.having != nil && s.groupBy == nil && s.selected == nil {
+		return fmt.Errorf("cannot have HAVING without GROUP BY or aggregated selected columns")
+	}
+	if s.lock != nil {
+		if s.lock.strength == "" {
+			return fmt.Errorf("SkipLocked and NoWait need ForUpdate or ForShare")
+		}
+		if s.lock.skipLocked && s.lock.noWait {
+			return fmt.Errorf("cannot have both SKIP LOCKED and NOWAIT")
+		}
+		if len(s.compounds) > 0 || s.distinct || s.groupBy != nil {
+			return fmt.Errorf("cannot lock rows of a query with UNION, DISTINCT or GROUP BY")
+		}
+	}
+	return nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderWith(ph *placeholders) (string, []interface{}, error) {
+	if len(s.ctes) == 0 {
+		return "", nil, nil
+	}
+	var recursive bool
+	var parts []string
+	var args []interface{}
+	for _, c := range s.ctes {
+		body, bodyArgs, err := c.query.renderSelect(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, bodyArgs...)
+		if c.recursive != nil {
+			recursive = true
+			rec, recArgs, err := c.recursive.renderSelect(ph)
+			if err != nil {
+				return "", nil, err
+			}
+			body = body + " " + compoundUnionAll + " " + rec
+			args = append(args, recArgs...)
+		}
+		parts = append(parts, c.name+" AS ("+body+")")
+	}
+	if recursive {
+		return "WITH RECURSIVE " + strings.Join(parts, ", "), args, nil
+	}
+	return "WITH " + strings.Join(parts, ", "), args, nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderSelectList(ph *placeholders) (string, []interface{}, error) {
+	columns := "*"
+	var args []interface{}
+	if s.selected != nil && len(s.selected.Columns) > 0 {
+		var err error
+		columns, args, err = s.selected.toSql(ph)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if s.distinct {
+		return "SELECT DISTINCT " + columns, args, nil
+	}
+	return "SELECT " + columns, args, nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderFrom(ph *placeholders) (string, []interface{}, error) {
 	if s.subQuery != nil {
-		s.subQuery.placeholderGenerator = s.placeholderGenerator
-		base += " " + "FROM (" + s.subQuery.q + " )"
-		args = append(args, s.subQuery.args...)
+		sub, args, err := s.subQuery.renderSelect(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		if s.alias != "" {
+			return "FROM (" + sub + ") AS " + s.alias, args, nil
+		}
+		return "FROM (" + sub + ")", args, nil
+	}
+	if s.alias != "" {
+		return "FROM " + s.table + " AS " + s.alias, nil, nil
 	}
-	// Joins
-	if s.joins != nil {
-		for _, join := range s.joins {
-			base += " " + join.String()
+	return "FROM " + s.table, nil, nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderJoins(ph *placeholders) (string, []interface{}, error) {
+	var joins []string
+	var args []interface{}
+	for _, join := range s.joins {
+		j, joinArgs, err := join.toSql(ph)
+		if err != nil {
+			return "", nil, err
 		}
+		joins = append(joins, j)
+		args = append(args, joinArgs...)
+	}
+	return strings.Join(joins, " "), args, nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderWhere(ph *placeholders) (string, []interface{}, error) {
+	if s.where == nil {
+		return "", nil, nil
+	}
+	where, args, err := s.where.toSql(ph)
+	if err != nil {
+		return "", nil, err
+	}
+	return "WHERE " + where, args, nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderGroupBy(_ *placeholders) (string, []interface{}, error) {
+	if s.groupBy == nil {
+		return "", nil, nil
+	}
+	return s.groupBy.String(), nil, nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderHaving(ph *placeholders) (string, []interface{}, error) {
+	if s.having == nil {
+		return "", nil, nil
 	}
-	// whereClause
-	if s.where != nil {
-		s.where.PlaceHolderGenerator = s.placeholderGenerator
-		where, whereArgs, err := s.where.ToSql()
+	having, args, err := s.having.toSql(ph)
+	if err != nil {
+		return "", nil, err
+	}
+	return "HAVING " + having, args, nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderWindows(_ *placeholders) (string, []interface{}, error) {
+	if len(s.windows) == 0 {
+		return "", nil, nil
+	}
+	var windows []string
+	for _, w := range s.windows {
+		windows = append(windows, w.String())
+	}
+	return "WINDOW " + strings.Join(windows, ","), nil, nil
+}
+
+func (s *QueryBuilder[OUTPUT]) renderCompounds(ph *placeholders) (string, []interface{}, error) {
+	var parts []string
+	var args []interface{}
+	for _, c := range s.compounds {
+		if err := c.query.validateCompoundOperand(); err != nil {
+			return "", nil, err
+		}
+		operand, operandArgs, err := c.query.renderSelect(ph)
 		if err != nil {
 			return "", nil, err
 		}
-		base += " WHERE " + where
-		args = append(args, whereArgs...)
+		parts = append(parts, c.op+" "+operand)
+		args = append(args, operandArgs...)
 	}
+	return strings.Join(parts, " "), args, nil
+}
 
-	// orderByClause
-	if s.orderBy != nil {
-		base += " " + s.orderBy.String()
+func (s *QueryBuilder[OUTPUT]) renderLock(_ *placeholders) (string, []interface{}, error) {
+	if s.lock == nil {
+		return "", nil, nil
 	}
+	if s.dialect != nil && !s.dialect.RowLocking {
+		return "", nil, nil
+	}
+	if s.dialect != nil && s.dialect.ShareLock != "" && s.lock.strength == "SHARE" && !s.lock.skipLocked && !s.lock.noWait {
+		return s.dialect.ShareLock, nil, nil
+	}
+	return s.lock.String(), nil, nil
+}
 
-	// GroupBy
-	if s.groupBy != nil {
-		base += " " + s.groupBy.String()
+// validateCompoundOperand returns an error if QueryBuilder cannot be used as right hand side
+// of a UNION, INTERSECT or EXCEPT, ORDER BY and LIMIT should be set on the left hand side
+// query since they apply to combined result.
+func (s *QueryBuilder[OUTPUT]) validateCompoundOperand() error {
+	if s.orderBy != nil || s.limit != nil || s.offset != nil {
+		return fmt.Errorf("cannot have ORDER BY, LIMIT or OFFSET in right hand side of a compound query, set them on the left hand side")
+	}
+	if s.lock != nil {
+		return fmt.Errorf("cannot lock rows of right hand side of a compound query")
+	}
+	if len(s.compounds) > 0 {
+		return fmt.Errorf("right hand side of a compound query cannot be a compound query itself, chain them instead")
+	}
+	if len(s.ctes) > 0 {
+		return fmt.Errorf("cannot have WITH in right hand side of a compound query, set it on the left hand side")
 	}
+	return nil
+}
 
-	// Limit
-	if s.limit != nil {
-		base += " " + s.limit.String()
+func (s *QueryBuilder[OUTPUT]) renderOrderBy(_ *placeholders) (string, []interface{}, error) {
+	if s.orderBy == nil {
+		return "", nil, nil
 	}
+	return s.orderBy.String(), nil, nil
+}
 
-	// Offset
+func (s *QueryBuilder[OUTPUT]) renderLimitOffset(_ *placeholders) (string, []interface{}, error) {
+	var parts []string
+	if s.limit != nil {
+		parts = append(parts, s.limit.String())
+	}
 	if s.offset != nil {
-		base += " " + s.offset.String()
+		parts = append(parts, s.offset.String())
 	}
-
-	return base, args, nil
+	return strings.Join(parts, " "), nil, nil
 }
 
 // ToSql creates sql query from QueryBuilder based on internal fields it would decide what kind
@@ -330,6 +708,13 @@ type orderByClause struct {
 	Columns [][2]string
 }
 
+func (o *orderByClause) clone() *orderByClause {
+	if o == nil {
+		return nil
+	}
+	return &orderByClause{Columns: slices.Clone(o.Columns)}
+}
+
 func (o orderByClause) String() string {
 	var tuples []string
 	for _, pair := range o.Columns {
@@ -342,6 +727,13 @@ type GroupBy struct {
 	Columns []string
 }
 
+func (g *GroupBy) clone() *GroupBy {
+	if g == nil {
+		return nil
+	}
+	return &GroupBy{Columns: slices.Clone(g.Columns)}
+}
+
 func (g GroupBy) String() string {
 	return fmt.Sprintf("GROUP BY %s", strings.Join(g.Columns, ","))
 }
@@ -369,12 +761,103 @@ type Join struct {
 	Type  joinType
 	Table string
 	On    JoinOn
+	// Alias if set is name of joined table in query.
+	Alias string
+	// SubQuery if set is joined instead of Table.
+	SubQuery SubQuery
+	// Condition if set is used as ON clause instead of On.
+	Condition *JoinCondition
 }
 
 func (j Join) String() string {
 	return fmt.Sprintf("%s JOIN %s ON %s", j.Type, j.Table, j.On.String())
 }
 
+func (j Join) toSql(ph *placeholders) (string, []interface{}, error) {
+	if j.SubQuery == nil && j.Alias == "" && j.Condition == nil {
+		return j.String(), nil, nil
+	}
+	var args []interface{}
+	target := j.Table
+	if j.SubQuery != nil {
+		sub, subArgs, err := j.SubQuery.renderSelect(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		target = "(" + sub + ")"
+		args = append(args, subArgs...)
+	}
+	if j.Alias != "" {
+		target += " AS " + j.Alias
+	}
+	on := j.On.String()
+	if j.Condition != nil {
+		var onArgs []interface{}
+		var err error
+		on, onArgs, err = j.Condition.toSql(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, onArgs...)
+	}
+	return fmt.Sprintf("%s JOIN %s ON %s", j.Type, target, on), args, nil
+}
+
+// JoinCondition is ON clause of a join that can have several conditions comparing columns
+// together or with values, create one using On.
+type JoinCondition struct {
+	where *whereClause
+	err   error
+}
+
+// On creates a JoinCondition with lhs and rhs columns being equal as its first condition.
+func On(lhs string, rhs string) *JoinCondition {
+	return (&JoinCondition{}).On(lhs, rhs)
+}
+
+// On appends a condition that lhs and rhs columns are equal as And.
+func (j *JoinCondition) On(lhs string, rhs string) *JoinCondition {
+	j.where = appendWhereClause(j.where, nextType_AND, &whereClause{raw: lhs + " = " + rhs})
+	return j
+}
+
+// OrOn appends a condition that lhs and rhs columns are equal as Or.
+func (j *JoinCondition) OrOn(lhs string, rhs string) *JoinCondition {
+	j.where = appendWhereClause(j.where, nextType_OR, &whereClause{raw: lhs + " = " + rhs})
+	return j
+}
+
+// Where appends a condition as And, it accepts same arguments as QueryBuilder Where
+// so values are sent to database as arguments.
+func (j *JoinCondition) Where(parts ...interface{}) *JoinCondition {
+	return j.addWhere(nextType_AND, parts...)
+}
+
+// OrWhere appends a condition as Or, it accepts same arguments as QueryBuilder Where.
+func (j *JoinCondition) OrWhere(parts ...interface{}) *JoinCondition {
+	return j.addWhere(nextType_OR, parts...)
+}
+
+func (j *JoinCondition) addWhere(typ string, parts ...interface{}) *JoinCondition {
+	c, err := newWhereClause(parts...)
+	if err != nil {
+		j.err = err
+		return j
+	}
+	j.where = appendWhereClause(j.where, typ, c)
+	return j
+}
+
+func (j *JoinCondition) toSql(ph *placeholders) (string, []interface{}, error) {
+	if j.err != nil {
+		return "", nil, j.err
+	}
+	if j.where == nil {
+		return "", nil, fmt.Errorf("join condition cannot be empty")
+	}
+	return j.where.toSql(ph)
+}
+
 type Limit struct {
 	N int
 }
@@ -391,14 +874,76 @@ func (o Offset) String() string {
 	return fmt.Sprintf("OFFSET %d", o.N)
 }
 
+type lock struct {
+	strength   string
+	skipLocked bool
+	noWait     bool
+}
+
+func (l lock) String() string {
+	base := "FOR " + l.strength
+	if l.skipLocked {
+		base += " SKIP LOCKED"
+	}
+	if l.noWait {
+		base += " NOWAIT"
+	}
+	return base
+}
+
+type window struct {
+	Name       string
+	Definition string
+}
+
+func (w window) String() string {
+	return fmt.Sprintf("%s AS (%s)", w.Name, w.Definition)
+}
+
 type selected struct {
 	Columns []string
+	// subQueries are scalar subqueries selected as a column, keyed by their position in Columns,
+	// Columns keeps their alias in that position.
+	subQueries map[int]SubQuery
+}
+
+func (s *selected) clone() *selected {
+	if s == nil {
+		return nil
+	}
+	c := &selected{Columns: slices.Clone(s.Columns)}
+	if s.subQueries != nil {
+		c.subQueries = maps.Clone(s.subQueries)
+	}
+	return c
 }
 
 func (s selected) String() string {
 	return fmt.Sprintf("%s", strings.Join(s.Columns, ","))
 }
 
+func (s selected) toSql(ph *placeholders) (string, []interface{}, error) {
+	if len(s.subQueries) == 0 {
+		return s.String(), nil, nil
+	}
+	var columns []string
+	var args []interface{}
+	for i, column := range s.Columns {
+		sub, isSubQuery := s.subQueries[i]
+		if !isSubQuery {
+			columns = append(columns, column)
+			continue
+		}
+		subSql, subArgs, err := sub.renderSelect(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, fmt.Sprintf("(%s) AS %s", subSql, column))
+		args = append(args, subArgs...)
+	}
+	return strings.Join(columns, ","), args, nil
+}
+
 // OrderBy adds an OrderBy section to QueryBuilder.
 func (q *QueryBuilder[OUTPUT]) OrderBy(column string, how string) *QueryBuilder[OUTPUT] {
 	q.SetSelect()
@@ -470,38 +1015,40 @@ func (q *QueryBuilder[OUTPUT]) FullOuterJoin(table string, onLhs string, onRhs s
 	return q
 }
 
-// Where Adds a where clause to query, if already have where clause append to it
-// as AndWhere.
-func (q *QueryBuilder[OUTPUT]) Where(parts ...interface{}) *QueryBuilder[OUTPUT] {
-	if q.where != nil {
-		return q.addWhere("AND", parts...)
-	}
-	if len(parts) == 1 {
-		if r, isRaw := parts[0].(*raw); isRaw {
-			q.where = &whereClause{raw: r.sql, args: r.args, PlaceHolderGenerator: q.placeholderGenerator}
-			return q
-		} else {
-			q.err = fmt.Errorf("when you have one argument passed to where, it should be *raw")
-			return q
-		}
+// JoinOn adds a join section to QueryBuilder with on as its ON clause, alias can be empty
+// and typ is one of JoinType constants.
+func (q *QueryBuilder[OUTPUT]) JoinOn(typ joinType, table string, alias string, on *JoinCondition) *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	q.joins = append(q.joins, &Join{
+		Type:      typ,
+		Table:     table,
+		Alias:     alias,
+		Condition: on,
+	})
+	return q
+}
 
-	} else if len(parts) == 2 {
-		if strings.Index(parts[0].(string), " ") == -1 {
-			// Equal mode
-			q.where = &whereClause{cond: cond{Lhs: parts[0].(string), Op: Eq, Rhs: parts[1]}, PlaceHolderGenerator: q.placeholderGenerator}
-		}
-		return q
-	} else if len(parts) == 3 {
-		// operator mode
-		q.where = &whereClause{cond: cond{Lhs: parts[0].(string), Op: binaryOp(parts[1].(string)), Rhs: parts[2]}, PlaceHolderGenerator: q.placeholderGenerator}
-		return q
-	} else if len(parts) > 3 && parts[1].(string) == "IN" {
-		q.where = &whereClause{cond: cond{Lhs: parts[0].(string), Op: binaryOp(parts[1].(string)), Rhs: parts[2:]}, PlaceHolderGenerator: q.placeholderGenerator}
-		return q
-	} else {
-		q.err = fmt.Errorf("wrong number of arguments passed to Where")
+// JoinQuery adds a join section to QueryBuilder that joins result of given subquery as alias,
+// typ is one of JoinType constants.
+func (q *QueryBuilder[OUTPUT]) JoinQuery(typ joinType, subQuery SubQuery, alias string, on *JoinCondition) *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	if alias == "" {
+		q.err = fmt.Errorf("joined subquery should have an alias")
 		return q
 	}
+	q.joins = append(q.joins, &Join{
+		Type:      typ,
+		Alias:     alias,
+		SubQuery:  subQuery,
+		Condition: on,
+	})
+	return q
+}
+
+// Where Adds a where clause to query, if already have where clause append to it
+// as AndWhere.
+func (q *QueryBuilder[OUTPUT]) Where(parts ...interface{}) *QueryBuilder[OUTPUT] {
+	return q.addWhere(nextType_AND, parts...)
 }
 
 type binaryOp string
@@ -516,32 +1063,53 @@ const (
 	Between = "BETWEEN"
 	Like    = "LIKE"
 	In      = "IN"
+
+	Exists    = "EXISTS"
+	NotExists = "NOT EXISTS"
 )
 
 type cond struct {
-	PlaceHolderGenerator func(n int) []string
-
 	Lhs string
 	Op  binaryOp
 	Rhs interface{}
+	// jsonKeys makes condition compare value of these keys inside JSON column Lhs, see WhereJSON.
+	jsonKeys []string
 }
 
-func (b cond) ToSql() (string, []interface{}, error) {
-	var phs []string
+func (b cond) toSql(ph *placeholders) (string, []interface{}, error) {
+	if len(b.jsonKeys) > 0 {
+		b.Lhs = ph.jsonExtract(b.Lhs, b.jsonKeys)
+	}
+	if b.Op == Exists || b.Op == NotExists {
+		sub, isSubQuery := b.Rhs.(SubQuery)
+		if !isSubQuery {
+			return "", nil, fmt.Errorf("Right hand side of Cond when operator is %s should be a subquery", b.Op)
+		}
+		subSql, args, err := sub.renderSelect(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s (%s)", b.Op, subSql), args, nil
+	}
+	if sub, isSubQuery := b.Rhs.(SubQuery); isSubQuery {
+		subSql, args, err := sub.renderSelect(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s %s (%s)", b.Lhs, b.Op, subSql), args, nil
+	}
 	if b.Op == In {
 		rhs, isInterfaceSlice := b.Rhs.([]interface{})
 		if isInterfaceSlice {
-			phs = b.PlaceHolderGenerator(len(rhs))
-			return fmt.Sprintf("%s IN (%s)", b.Lhs, strings.Join(phs, ",")), rhs, nil
+			return fmt.Sprintf("%s IN (%s)", b.Lhs, strings.Join(ph.next(len(rhs)), ",")), rhs, nil
 		} else if rawThing, isRaw := b.Rhs.(*raw); isRaw {
-			return fmt.Sprintf("%s IN (%s)", b.Lhs, rawThing.sql), rawThing.args, nil
+			return fmt.Sprintf("%s IN (%s)", b.Lhs, ph.rebind(rawThing.sql)), rawThing.args, nil
 		} else {
-			return "", nil, fmt.Errorf("Right hand side of Cond when operator is IN should be either a interface{} slice or *raw")
+			return "", nil, fmt.Errorf("Right hand side of Cond when operator is IN should be either a interface{} slice, *raw or a subquery")
 		}
 
 	} else {
-		phs = b.PlaceHolderGenerator(1)
-		return fmt.Sprintf("%s %s %s", b.Lhs, b.Op, pop(&phs)), []interface{}{b.Rhs}, nil
+		return fmt.Sprintf("%s %s %s", b.Lhs, b.Op, ph.next(1)[0]), []interface{}{b.Rhs}, nil
 	}
 }
 
@@ -551,24 +1119,50 @@ const (
 )
 
 type whereClause struct {
-	PlaceHolderGenerator func(n int) []string
-	nextTyp              string
-	next                 *whereClause
+	nextTyp string
+	next    *whereClause
 	cond
 	raw  string
 	args []interface{}
+	// group is a condition chain rendered in parentheses.
+	group *whereClause
+}
+
+// groupWhereClause wraps condition chain w in parentheses, so conditions appended to it
+// are not mixed with its OR conditions.
+func groupWhereClause(w *whereClause) *whereClause {
+	if w == nil || w.next == nil {
+		return w
+	}
+	return &whereClause{group: w}
 }
 
-func (w whereClause) ToSql() (string, []interface{}, error) {
+// clone returns a copy of condition chain starting from w.
+func (w *whereClause) clone() *whereClause {
+	if w == nil {
+		return nil
+	}
+	c := *w
+	c.next = w.next.clone()
+	c.group = w.group.clone()
+	return &c
+}
+
+func (w whereClause) toSql(ph *placeholders) (string, []interface{}, error) {
 	var base string
 	var args []interface{}
 	var err error
-	if w.raw != "" {
-		base = w.raw
+	if w.group != nil {
+		base, args, err = w.group.toSql(ph)
+		if err != nil {
+			return "", nil, err
+		}
+		base = "(" + base + ")"
+	} else if w.raw != "" {
+		base = ph.rebind(w.raw)
 		args = w.args
 	} else {
-		w.cond.PlaceHolderGenerator = w.PlaceHolderGenerator
-		base, args, err = w.cond.ToSql()
+		base, args, err = w.cond.toSql(ph)
 		if err != nil {
 			return "", nil, err
 		}
@@ -576,26 +1170,35 @@ func (w whereClause) ToSql() (string, []interface{}, error) {
 	if w.next == nil {
 		return base, args, nil
 	}
-	if w.next != nil {
-		next, nextArgs, err := w.next.ToSql()
-		if err != nil {
-			return "", nil, err
-		}
-		base += " " + w.nextTyp + " " + next
-		args = append(args, nextArgs...)
-		return base, args, nil
+	next, nextArgs, err := w.next.toSql(ph)
+	if err != nil {
+		return "", nil, err
 	}
-
+	base += " " + w.nextTyp + " " + next
+	args = append(args, nextArgs...)
 	return base, args, nil
 }
 
 //func (q *QueryBuilder[OUTPUT]) WhereKeyValue(m map) {}
 
-// WhereIn adds a where clause to QueryBuilder using In operator.
+// WhereIn adds a where clause to QueryBuilder using In operator, values can be
+// list of values, a single *raw or a single subquery.
 func (q *QueryBuilder[OUTPUT]) WhereIn(column string, values ...interface{}) *QueryBuilder[OUTPUT] {
 	return q.Where(append([]interface{}{column, In}, values...)...)
 }
 
+// WhereExists adds a where clause to QueryBuilder that checks subquery has any rows.
+func (q *QueryBuilder[OUTPUT]) WhereExists(subQuery SubQuery) *QueryBuilder[OUTPUT] {
+	q.where = appendWhereClause(q.where, nextType_AND, &whereClause{cond: cond{Op: Exists, Rhs: subQuery}})
+	return q
+}
+
+// WhereNotExists adds a where clause to QueryBuilder that checks subquery has no rows.
+func (q *QueryBuilder[OUTPUT]) WhereNotExists(subQuery SubQuery) *QueryBuilder[OUTPUT] {
+	q.where = appendWhereClause(q.where, nextType_AND, &whereClause{cond: cond{Op: NotExists, Rhs: subQuery}})
+	return q
+}
+
 // AndWhere appends a where clause to query builder as And where clause.
 func (q *QueryBuilder[OUTPUT]) AndWhere(parts ...interface{}) *QueryBuilder[OUTPUT] {
 	return q.addWhere(nextType_AND, parts...)
@@ -607,37 +1210,99 @@ func (q *QueryBuilder[OUTPUT]) OrWhere(parts ...interface{}) *QueryBuilder[OUTPU
 }
 
 func (q *QueryBuilder[OUTPUT]) addWhere(typ string, parts ...interface{}) *QueryBuilder[OUTPUT] {
-	w := q.where
-	for {
-		if w == nil {
-			break
-		} else if w.next == nil {
-			w.next = &whereClause{PlaceHolderGenerator: q.placeholderGenerator}
-			w.nextTyp = typ
-			w = w.next
-			break
-		} else {
-			w = w.next
-		}
+	c, err := newWhereClause(parts...)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	if err = q.useBlindIndex(c); err != nil {
+		q.err = err
+		return q
 	}
+	q.where = appendWhereClause(q.where, typ, c)
+	return q
+}
+
+// Having adds a having clause to query, it accepts same arguments as Where and
+// if query already has a having clause, appends to it as And.
+func (q *QueryBuilder[OUTPUT]) Having(parts ...interface{}) *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	q.having = q.appendCondition(q.having, nextType_AND, parts...)
+	return q
+}
+
+// OrHaving appends a having clause to query builder as Or having clause.
+func (q *QueryBuilder[OUTPUT]) OrHaving(parts ...interface{}) *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	q.having = q.appendCondition(q.having, nextType_OR, parts...)
+	return q
+}
+
+// appendCondition creates a condition from parts and appends it to the end of w using typ,
+// if parts are not valid it sets QueryBuilder error and returns w.
+func (q *QueryBuilder[OUTPUT]) appendCondition(w *whereClause, typ string, parts ...interface{}) *whereClause {
+	c, err := newWhereClause(parts...)
+	if err != nil {
+		q.err = err
+		return w
+	}
+	return appendWhereClause(w, typ, c)
+}
+
+// appendWhereClause appends c to the end of w using typ.
+func appendWhereClause(w *whereClause, typ string, c *whereClause) *whereClause {
 	if w == nil {
-		w = &whereClause{PlaceHolderGenerator: q.placeholderGenerator}
+		return c
+	}
+	last := w
+	for last.next != nil {
+		last = last.next
 	}
+	last.next = c
+	last.nextTyp = typ
+	return w
+}
+
+// newWhereClause creates a single condition from parts which can be one of:
+// (*raw), (column, value), (column, operator, value) or (column, IN, values...),
+// value can also be a subquery.
+func newWhereClause(parts ...interface{}) (*whereClause, error) {
 	if len(parts) == 1 {
-		w.raw = parts[0].(*raw).sql
-		w.args = parts[0].(*raw).args
-		return q
-	} else if len(parts) == 2 {
+		r, isRaw := parts[0].(*raw)
+		if !isRaw {
+			return nil, fmt.Errorf("when you have one argument passed to where, it should be *raw")
+		}
+		return &whereClause{raw: r.sql, args: r.args}, nil
+	}
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("wrong number of arguments passed to Where")
+	}
+	column, isString := parts[0].(string)
+	if !isString {
+		return nil, fmt.Errorf("first argument of where should be column name, got %T", parts[0])
+	}
+	if len(parts) == 2 {
 		// Equal mode
-		w.cond = cond{Lhs: parts[0].(string), Op: Eq, Rhs: parts[1]}
-		return q
-	} else if len(parts) == 3 {
+		return &whereClause{cond: cond{Lhs: column, Op: Eq, Rhs: parts[1]}}, nil
+	}
+	op, isString := parts[1].(string)
+	if !isString {
+		return nil, fmt.Errorf("operator of where should be a string, got %T", parts[1])
+	}
+	if op == In {
+		if r, isRaw := parts[2].(*raw); isRaw && len(parts) == 3 {
+			return &whereClause{cond: cond{Lhs: column, Op: In, Rhs: r}}, nil
+		}
+		if sub, isSubQuery := parts[2].(SubQuery); isSubQuery && len(parts) == 3 {
+			return &whereClause{cond: cond{Lhs: column, Op: In, Rhs: sub}}, nil
+		}
+		return &whereClause{cond: cond{Lhs: column, Op: In, Rhs: parts[2:]}}, nil
+	}
+	if len(parts) == 3 {
 		// operator mode
-		w.cond = cond{Lhs: parts[0].(string), Op: binaryOp(parts[1].(string)), Rhs: parts[2]}
-		return q
-	} else {
-		panic("wrong number of arguments passed to Where")
+		return &whereClause{cond: cond{Lhs: column, Op: binaryOp(op), Rhs: parts[2]}}, nil
 	}
+	return nil, fmt.Errorf("wrong number of arguments passed to Where")
 }
 
 // Offset adds offset section to query builder.
@@ -660,6 +1325,13 @@ func (q *QueryBuilder[OUTPUT]) Table(t string) *QueryBuilder[OUTPUT] {
 	return q
 }
 
+// Alias sets name of table or subquery QueryBuilder selects from, so columns
+// can be referenced using alias, useful when joining a table with itself.
+func (q *QueryBuilder[OUTPUT]) Alias(alias string) *QueryBuilder[OUTPUT] {
+	q.alias = alias
+	return q
+}
+
 // SetSelect sets query type of QueryBuilder to Select.
 func (q *QueryBuilder[OUTPUT]) SetSelect() *QueryBuilder[OUTPUT] {
 	q.typ = queryTypeSELECT
@@ -676,6 +1348,13 @@ func (q *QueryBuilder[OUTPUT]) GroupBy(columns ...string) *QueryBuilder[OUTPUT]
 	return q
 }
 
+// Distinct makes QueryBuilder select only distinct rows.
+func (q *QueryBuilder[OUTPUT]) Distinct() *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	q.distinct = true
+	return q
+}
+
 // Select adds columns to QueryBuilder select field list.
 func (q *QueryBuilder[OUTPUT]) Select(columns ...string) *QueryBuilder[OUTPUT] {
 	q.SetSelect()
@@ -686,21 +1365,150 @@ func (q *QueryBuilder[OUTPUT]) Select(columns ...string) *QueryBuilder[OUTPUT] {
 	return q
 }
 
+// SelectQuery adds a scalar subquery as a column named alias to QueryBuilder select field list.
+func (q *QueryBuilder[OUTPUT]) SelectQuery(subQuery SubQuery, alias string) *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	if alias == "" {
+		q.err = fmt.Errorf("selected subquery should have an alias")
+		return q
+	}
+	if q.selected == nil {
+		q.selected = &selected{}
+	}
+	if q.selected.subQueries == nil {
+		q.selected.subQueries = map[int]SubQuery{}
+	}
+	q.selected.subQueries[len(q.selected.Columns)] = subQuery
+	q.selected.Columns = append(q.selected.Columns, alias)
+	return q
+}
+
 // FromQuery sets subquery of QueryBuilder to be given subquery so
 // when doing select instead of from table we do from(subquery).
-func (q *QueryBuilder[OUTPUT]) FromQuery(subQuery *QueryBuilder[OUTPUT]) *QueryBuilder[OUTPUT] {
+func (q *QueryBuilder[OUTPUT]) FromQuery(subQuery SubQuery) *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	q.subQuery = subQuery
+	return q
+}
+
+type cte struct {
+	name  string
+	query SubQuery
+	// recursive is the recursive part of a WITH RECURSIVE query that is
+	// combined with query using UNION ALL.
+	recursive SubQuery
+}
+
+func (q *QueryBuilder[OUTPUT]) addCTE(c cte) *QueryBuilder[OUTPUT] {
 	q.SetSelect()
-	subQuery.SetSelect()
-	subQuery.placeholderGenerator = q.placeholderGenerator
-	subQueryString, args, err := subQuery.ToSql()
-	q.err = err
-	q.subQuery = &struct {
-		q                    string
-		args                 []interface{}
-		placeholderGenerator func(n int) []string
-	}{
-		subQueryString, args, q.placeholderGenerator,
+	if c.name == "" {
+		q.err = fmt.Errorf("name of a common table expression cannot be empty")
+		return q
+	}
+	for _, existing := range q.ctes {
+		if existing.name == c.name {
+			q.err = fmt.Errorf("common table expression %s is already defined", c.name)
+			return q
+		}
 	}
+	q.ctes = append(q.ctes, c)
+	return q
+}
+
+// With adds a named subquery to WITH clause of query so it can be used as a table
+// in Table, joins or other subqueries.
+func (q *QueryBuilder[OUTPUT]) With(name string, subQuery SubQuery) *QueryBuilder[OUTPUT] {
+	return q.addCTE(cte{name: name, query: subQuery})
+}
+
+// WithRecursive adds a recursive named subquery to WITH clause of query, anchor selects
+// the starting rows and recursive is combined with it using UNION ALL and can refer to name
+// to walk hierarchical data like trees.
+func (q *QueryBuilder[OUTPUT]) WithRecursive(name string, anchor SubQuery, recursive SubQuery) *QueryBuilder[OUTPUT] {
+	return q.addCTE(cte{name: name, query: anchor, recursive: recursive})
+}
+
+const (
+	compoundUnion     = "UNION"
+	compoundUnionAll  = "UNION ALL"
+	compoundIntersect = "INTERSECT"
+	compoundExcept    = "EXCEPT"
+)
+
+type compound struct {
+	op    string
+	query SubQuery
+}
+
+func (q *QueryBuilder[OUTPUT]) addCompound(op string, other *QueryBuilder[OUTPUT]) *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	other.SetSelect()
+	q.compounds = append(q.compounds, compound{op: op, query: other})
+	return q
+}
+
+// Union combines results of QueryBuilder and other query removing duplicate rows, OrderBy, Limit
+// and Offset of QueryBuilder apply to the combined result.
+func (q *QueryBuilder[OUTPUT]) Union(other *QueryBuilder[OUTPUT]) *QueryBuilder[OUTPUT] {
+	return q.addCompound(compoundUnion, other)
+}
+
+// UnionAll is like Union but keeps duplicate rows.
+func (q *QueryBuilder[OUTPUT]) UnionAll(other *QueryBuilder[OUTPUT]) *QueryBuilder[OUTPUT] {
+	return q.addCompound(compoundUnionAll, other)
+}
+
+// Intersect keeps only rows that are in results of both QueryBuilder and other query.
+func (q *QueryBuilder[OUTPUT]) Intersect(other *QueryBuilder[OUTPUT]) *QueryBuilder[OUTPUT] {
+	return q.addCompound(compoundIntersect, other)
+}
+
+// Except keeps rows of QueryBuilder results that are not in other query results.
+func (q *QueryBuilder[OUTPUT]) Except(other *QueryBuilder[OUTPUT]) *QueryBuilder[OUTPUT] {
+	return q.addCompound(compoundExcept, other)
+}
+
+func (q *QueryBuilder[OUTPUT]) getLock() *lock {
+	q.SetSelect()
+	if q.lock == nil {
+		q.lock = &lock{}
+	}
+	return q.lock
+}
+
+// ForUpdate locks selected rows for update until end of the transaction query runs in,
+// it's ignored on dialects without row locking like SQLite that lock whole database instead.
+func (q *QueryBuilder[OUTPUT]) ForUpdate() *QueryBuilder[OUTPUT] {
+	q.getLock().strength = "UPDATE"
+	return q
+}
+
+// ForShare locks selected rows so other transactions can read but not change them until end
+// of the transaction query runs in, it's ignored on dialects without row locking. It's rendered
+// as LOCK IN SHARE MODE on MySQL, using it with SkipLocked or NoWait needs MySQL 8.0.
+func (q *QueryBuilder[OUTPUT]) ForShare() *QueryBuilder[OUTPUT] {
+	q.getLock().strength = "SHARE"
+	return q
+}
+
+// SkipLocked makes a locking query skip rows that are already locked instead of waiting,
+// which is useful for claiming jobs from a queue table.
+func (q *QueryBuilder[OUTPUT]) SkipLocked() *QueryBuilder[OUTPUT] {
+	q.getLock().skipLocked = true
+	return q
+}
+
+// NoWait makes a locking query fail instead of waiting when a row is already locked.
+func (q *QueryBuilder[OUTPUT]) NoWait() *QueryBuilder[OUTPUT] {
+	q.getLock().noWait = true
+	return q
+}
+
+// Window adds a named window to WINDOW clause of query, so you can use it in
+// selected columns, for example Window("w", "PARTITION BY user_id ORDER BY created_at").
+func (q *QueryBuilder[OUTPUT]) Window(name string, definition string) *QueryBuilder[OUTPUT] {
+	q.SetSelect()
+	q.windows = append(q.windows, window{Name: name, Definition: definition})
 	return q
 }
 
@@ -717,13 +1525,35 @@ func (q *QueryBuilder[OUTPUT]) Set(keyValues ...any) *QueryBuilder[OUTPUT] {
 	q.SetUpdate()
 	for i := 0; i < len(keyValues); i++ {
 		if i != 0 && i%2 == 1 {
-			q.sets = append(q.sets, [2]any{keyValues[i-1], keyValues[i]})
+			q.sets = append(q.sets, q.columnValues(keyValues[i-1], keyValues[i])...)
 		}
 	}
 	return q
 }
 
+// WithContext sets context that finishers use for running the query, it will be
+// passed to database driver and connection interceptors.
+func (q *QueryBuilder[OUTPUT]) WithContext(ctx context.Context) *QueryBuilder[OUTPUT] {
+	q.ctx = ctx
+	return q
+}
+
+func (q *QueryBuilder[OUTPUT]) context() context.Context {
+	if q.ctx == nil {
+		return context.Background()
+	}
+	return q.ctx
+}
+
 func (q *QueryBuilder[OUTPUT]) SetDialect(dialect *Dialect) *QueryBuilder[OUTPUT] {
+	if dialect == nil {
+		// schemas without a connection have no dialect.
+		if q.err == nil {
+			q.err = fmt.Errorf("dialect cannot be nil: %w", ErrNoConnection)
+		}
+		return q
+	}
+	q.dialect = dialect
 	q.placeholderGenerator = dialect.PlaceHolderGenerator
 	return q
 }
@@ -747,6 +1577,12 @@ func NewQueryBuilder[OUTPUT any](s *schema) *QueryBuilder[OUTPUT] {
 	return &QueryBuilder[OUTPUT]{schema: s}
 }
 
+// queryBuilderWithError creates a QueryBuilder that carries given error, so
+// it will be returned by finishers.
+func queryBuilderWithError[OUTPUT any](err error) *QueryBuilder[OUTPUT] {
+	return &QueryBuilder[OUTPUT]{err: err}
+}
+
 type insertStmt struct {
 	PlaceHolderGenerator func(n int) []string
 	Table                string
@@ -794,6 +1630,80 @@ func postgresPlaceholder(n int) []string {
 	return output
 }
 
+// placeholders generates placeholders of a dialect for a whole query, it keeps
+// count of generated ones so each placeholder gets its correct position
+// no matter which clause it belongs to.
+type placeholders struct {
+	generator func(n int) []string
+	// generated keeps placeholders generated so far, it grows by doubling so generator
+	// is not called for each placeholder.
+	generated []string
+	count     int
+	// dialect is used for dialect specific parts of conditions, it can be nil.
+	dialect *Dialect
+}
+
+func newPlaceholders(generator func(n int) []string) *placeholders {
+	if generator == nil {
+		generator = questionMarks
+	}
+	return &placeholders{generator: generator}
+}
+
+// jsonExtract renders value of keys inside JSON column using dialect, -> and ->> operators
+// are used when there is no dialect.
+func (p *placeholders) jsonExtract(column string, keys []string) string {
+	if p.dialect != nil && p.dialect.JSONExtract != nil {
+		return p.dialect.JSONExtract(column, keys)
+	}
+	return jsonArrowExtract(column, keys)
+}
+
+// next returns n next placeholders.
+func (p *placeholders) next(n int) []string {
+	if p.count+n > len(p.generated) {
+		size := 2 * len(p.generated)
+		if size < p.count+n {
+			size = p.count + n
+		}
+		p.generated = p.generator(size)
+	}
+	phs := p.generated[p.count : p.count+n]
+	p.count += n
+	return phs
+}
+
+// rebind replaces ? placeholders of given raw sql with next placeholders, ? inside quoted
+// strings and identifiers are kept and ?? is written as a literal ?, like Postgres jsonb
+// operators ?, ?| and ?& that are written as ??, ??| and ??&.
+func (p *placeholders) rebind(sql string) string {
+	if !strings.Contains(sql, "?") {
+		return sql
+	}
+	var b strings.Builder
+	var quote rune
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			// a doubled quote escapes itself, it closes and reopens the quoted string.
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?' && i+1 < len(runes) && runes[i+1] == '?':
+			i++
+		case r == '?':
+			b.WriteString(p.next(1)[0])
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 func questionMarks(n int) []string {
 	output := []string{}
 	for i := 0; i < n; i++ {
@@ -53,6 +53,28 @@ func TestSelect(t *testing.T) {
 		assert.Equal(t, "SELECT * FROM users GROUP BY created_at,updated_at", str)
 	})
 
+	t.Run("select with having", func(t *testing.T) {
+		s := NewQueryBuilder[Dummy](nil).
+			SetDialect(Dialects.MySQL).
+			Table("orders").
+			Select("user_id", "SUM(amount)").
+			GroupBy("user_id").
+			Having("SUM(amount)", ">", 100).
+			OrHaving(Raw("COUNT(id) > ?", 10))
+		str, args, err := s.ToSql()
+		assert.NoError(t, err)
+		assert.EqualValues(t, []interface{}{100, 10}, args)
+		assert.Equal(t, "SELECT user_id,SUM(amount) FROM orders GROUP BY user_id HAVING SUM(amount) > ? OR COUNT(id) > ?", str)
+	})
+
+	t.Run("select distinct", func(t *testing.T) {
+		s := NewQueryBuilder[Dummy](nil).Table("users").Select("name").Distinct()
+		str, args, err := s.ToSql()
+		assert.NoError(t, err)
+		assert.Empty(t, args)
+		assert.Equal(t, "SELECT DISTINCT name FROM users", str)
+	})
+
 	t.Run("Select with limit", func(t *testing.T) {
 		s := NewQueryBuilder[Dummy](nil).Table("users").Limit(10)
 		str, args, err := s.ToSql()
@@ -94,7 +116,7 @@ func TestSelect(t *testing.T) {
 		sql, args, err := s.ToSql()
 		assert.NoError(t, err)
 		assert.EqualValues(t, []interface{}{10}, args)
-		assert.Equal(t, `SELECT * FROM (SELECT * FROM users WHERE age < ? )`, sql)
+		assert.Equal(t, `SELECT * FROM (SELECT * FROM users WHERE age < ?)`, sql)
 
 	})
 
@@ -135,6 +157,24 @@ func TestSelect(t *testing.T) {
 		assert.EqualValues(t, []interface{}{1, 10}, args)
 		assert.Equal(t, `SELECT * FROM users WHERE id = ? AND age < ?`, sql)
 	})
+	t.Run("wrong number of arguments for where", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).
+			SetDialect(Dialects.MySQL).
+			Table("users").
+			Where("id", 1).
+			AndWhere("age", "<", 10, 11).
+			SetSelect().
+			ToSql()
+		assert.Error(t, err)
+	})
+	t.Run("nil dialect", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).
+			SetDialect(nil).
+			Table("users").
+			SetSelect().
+			ToSql()
+		assert.ErrorIs(t, err, ErrNoConnection)
+	})
 	t.Run("no sql type matched", func(t *testing.T) {
 		sql, args, err := NewQueryBuilder[Dummy](nil).ToSql()
 		assert.Error(t, err)
@@ -155,6 +195,19 @@ func TestSelect(t *testing.T) {
 		assert.EqualValues(t, []interface{}{10}, args)
 		assert.Equal(t, `SELECT * FROM users WHERE id IN (SELECT user_id FROM user_books WHERE book_id = ?)`, sql)
 	})
+	t.Run("or where as first where", func(t *testing.T) {
+		sql, args, err :=
+			NewQueryBuilder[Dummy](nil).
+				SetDialect(Dialects.MySQL).
+				Table("users").
+				OrWhere("id", 1).
+				SetSelect().
+				ToSql()
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, []interface{}{1}, args)
+		assert.Equal(t, `SELECT * FROM users WHERE id = ?`, sql)
+	})
 	t.Run("where in", func(t *testing.T) {
 		sql, args, err :=
 			NewQueryBuilder[Dummy](nil).
@@ -234,3 +287,440 @@ func TestPostgresPlaceholder(t *testing.T) {
 		assert.EqualValues(t, []string{"$1", "$2", "$3", "$4", "$5"}, phs)
 	})
 }
+
+func TestSelectClauseOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(d *Dialect) *QueryBuilder[Dummy]
+		expected map[string]string
+		args     []interface{}
+	}{
+		{
+			name: "group by comes before order by",
+			build: func(d *Dialect) *QueryBuilder[Dummy] {
+				return NewQueryBuilder[Dummy](nil).SetDialect(d).Table("users").
+					OrderBy("name", ASC).
+					GroupBy("name").
+					Select("name", "COUNT(id)")
+			},
+			expected: map[string]string{
+				"mysql":    "SELECT name,COUNT(id) FROM users GROUP BY name ORDER BY name ASC",
+				"postgres": "SELECT name,COUNT(id) FROM users GROUP BY name ORDER BY name ASC",
+				"sqlite3":  "SELECT name,COUNT(id) FROM users GROUP BY name ORDER BY name ASC",
+			},
+		},
+		{
+			name: "all clauses",
+			build: func(d *Dialect) *QueryBuilder[Dummy] {
+				return NewQueryBuilder[Dummy](nil).SetDialect(d).Table("orders").
+					Offset(20).
+					Limit(10).
+					OrderBy("total", DESC).
+					Window("w", "PARTITION BY user_id").
+					Having("SUM(amount)", ">", 100).
+					GroupBy("user_id").
+					Where("status", "paid").
+					AndWhere("amount", ">", 10).
+					LeftJoin("users", "users.id", "orders.user_id").
+					Select("user_id", "SUM(amount) AS total", "RANK() OVER w")
+			},
+			expected: map[string]string{
+				"mysql":    "SELECT user_id,SUM(amount) AS total,RANK() OVER w FROM orders LEFT JOIN users ON users.id = orders.user_id WHERE status = ? AND amount > ? GROUP BY user_id HAVING SUM(amount) > ? WINDOW w AS (PARTITION BY user_id) ORDER BY total DESC LIMIT 10 OFFSET 20",
+				"postgres": "SELECT user_id,SUM(amount) AS total,RANK() OVER w FROM orders LEFT JOIN users ON users.id = orders.user_id WHERE status = $1 AND amount > $2 GROUP BY user_id HAVING SUM(amount) > $3 WINDOW w AS (PARTITION BY user_id) ORDER BY total DESC LIMIT 10 OFFSET 20",
+				"sqlite3":  "SELECT user_id,SUM(amount) AS total,RANK() OVER w FROM orders LEFT JOIN users ON users.id = orders.user_id WHERE status = ? AND amount > ? GROUP BY user_id HAVING SUM(amount) > ? WINDOW w AS (PARTITION BY user_id) ORDER BY total DESC LIMIT 10 OFFSET 20",
+			},
+			args: []interface{}{"paid", 10, 100},
+		},
+		{
+			name: "placeholders are numbered across subquery, where in and raw",
+			build: func(d *Dialect) *QueryBuilder[Dummy] {
+				return NewQueryBuilder[Dummy](nil).SetDialect(d).
+					FromQuery(NewQueryBuilder[Dummy](nil).SetDialect(d).Table("users").Where("age", ">", 18)).
+					WhereIn("id", 1, 2).
+					OrWhere(Raw("name = ? OR name = ?", "a", "b"))
+			},
+			expected: map[string]string{
+				"mysql":    "SELECT * FROM (SELECT * FROM users WHERE age > ?) WHERE id IN (?,?) OR name = ? OR name = ?",
+				"postgres": "SELECT * FROM (SELECT * FROM users WHERE age > $1) WHERE id IN ($2,$3) OR name = $4 OR name = $5",
+				"sqlite3":  "SELECT * FROM (SELECT * FROM users WHERE age > ?) WHERE id IN (?,?) OR name = ? OR name = ?",
+			},
+			args: []interface{}{18, 1, 2, "a", "b"},
+		},
+		{
+			name: "quoted strings and escaped question marks in raw are not placeholders",
+			build: func(d *Dialect) *QueryBuilder[Dummy] {
+				return NewQueryBuilder[Dummy](nil).SetDialect(d).Table("users").
+					Where(Raw("title <> 'why?' AND \"who?\" = ? AND meta ?? 'plan' AND tags ??| ?", "a", "b")).
+					SetSelect()
+			},
+			expected: map[string]string{
+				"mysql":    "SELECT * FROM users WHERE title <> 'why?' AND \"who?\" = ? AND meta ? 'plan' AND tags ?| ?",
+				"postgres": "SELECT * FROM users WHERE title <> 'why?' AND \"who?\" = $1 AND meta ? 'plan' AND tags ?| $2",
+				"sqlite3":  "SELECT * FROM users WHERE title <> 'why?' AND \"who?\" = ? AND meta ? 'plan' AND tags ?| ?",
+			},
+			args: []interface{}{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		for _, d := range []*Dialect{Dialects.MySQL, Dialects.PostgreSQL, Dialects.SQLite3} {
+			t.Run(tt.name+" "+d.DriverName, func(t *testing.T) {
+				sql, args, err := tt.build(d).ToSql()
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected[d.DriverName], sql)
+				assert.EqualValues(t, tt.args, args)
+			})
+		}
+	}
+}
+
+func TestSelectValidation(t *testing.T) {
+	t.Run("offset without limit", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.MySQL).Table("users").Offset(10).ToSql()
+		assert.Error(t, err)
+		_, _, err = NewQueryBuilder[Dummy](nil).SetDialect(Dialects.SQLite3).Table("users").Offset(10).ToSql()
+		assert.Error(t, err)
+		sql, _, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).Table("users").Offset(10).ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users OFFSET 10", sql)
+	})
+	t.Run("negative limit", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("users").Limit(-1).ToSql()
+		assert.Error(t, err)
+	})
+	t.Run("set in select query", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("users").Set("name", "amirreza").OrderBy("id", ASC).ToSql()
+		assert.Error(t, err)
+	})
+	t.Run("having without group by", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.MySQL).Table("users").Having("age", ">", 10).ToSql()
+		assert.Error(t, err)
+	})
+	t.Run("table and subquery", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("users").FromQuery(NewQueryBuilder[Dummy](nil).Table("users")).ToSql()
+		assert.Error(t, err)
+	})
+}
+
+func TestUpdatePlaceholders(t *testing.T) {
+	sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+		Table("users").
+		Set("name", "amirreza", "age", 30).
+		Where("id", 1).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `UPDATE users SET name=$1,age=$2 WHERE id = $3`, sql)
+	assert.EqualValues(t, []interface{}{"amirreza", 30, 1}, args)
+}
+
+func TestCompound(t *testing.T) {
+	t.Run("union with placeholders renumbered", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			Table("users").Select("id", "name").Where("age", ">", 10).
+			Union(NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+				Table("admins").Select("id", "name").Where("level", 2)).
+			OrderBy("name", ASC).
+			Limit(5).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT id,name FROM users WHERE age > $1 UNION SELECT id,name FROM admins WHERE level = $2 ORDER BY name ASC LIMIT 5`, sql)
+		assert.EqualValues(t, []interface{}{10, 2}, args)
+	})
+	t.Run("chained operators", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).
+			Table("users").Where("age", 10).
+			UnionAll(NewQueryBuilder[Dummy](nil).Table("admins").Where("age", 20)).
+			Intersect(NewQueryBuilder[Dummy](nil).Table("authors")).
+			Except(NewQueryBuilder[Dummy](nil).Table("banned").Where("age", 30)).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM users WHERE age = ? UNION ALL SELECT * FROM admins WHERE age = ? INTERSECT SELECT * FROM authors EXCEPT SELECT * FROM banned WHERE age = ?`, sql)
+		assert.EqualValues(t, []interface{}{10, 20, 30}, args)
+	})
+	t.Run("order by in right hand side", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("users").
+			Union(NewQueryBuilder[Dummy](nil).Table("admins").OrderBy("id", ASC)).
+			ToSql()
+		assert.Error(t, err)
+	})
+	t.Run("compound in right hand side", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("users").
+			Union(NewQueryBuilder[Dummy](nil).Table("admins").Union(NewQueryBuilder[Dummy](nil).Table("authors"))).
+			ToSql()
+		assert.Error(t, err)
+	})
+}
+
+func TestWith(t *testing.T) {
+	t.Run("named subquery", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			With("adults", NewQueryBuilder[Dummy](nil).Table("users").Where("age", ">", 18)).
+			Table("adults").
+			Where("name", "amirreza").
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `WITH adults AS (SELECT * FROM users WHERE age > $1) SELECT * FROM adults WHERE name = $2`, sql)
+		assert.EqualValues(t, []interface{}{18, "amirreza"}, args)
+	})
+	t.Run("recursive", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			WithRecursive("tree",
+				NewQueryBuilder[Dummy](nil).Table("categories").Where("id", 1),
+				NewQueryBuilder[Dummy](nil).Table("categories").Select("categories.*").InnerJoin("tree", "categories.parent_id", "tree.id").Where("categories.active", true),
+			).
+			Table("tree").
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `WITH RECURSIVE tree AS (SELECT * FROM categories WHERE id = $1 UNION ALL SELECT categories.* FROM categories INNER JOIN tree ON categories.parent_id = tree.id WHERE categories.active = $2) SELECT * FROM tree`, sql)
+		assert.EqualValues(t, []interface{}{1, true}, args)
+	})
+	t.Run("duplicate name", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).
+			With("a", NewQueryBuilder[Dummy](nil).Table("users")).
+			With("a", NewQueryBuilder[Dummy](nil).Table("admins")).
+			Table("a").
+			ToSql()
+		assert.Error(t, err)
+	})
+}
+
+func TestSubQueries(t *testing.T) {
+	t.Run("where operator", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			Table("users").
+			Where("name", "amirreza").
+			Where("age", ">", NewQueryBuilder[Dummy](nil).Table("users").Select("AVG(age)").Where("country", "IR")).
+			Limit(10).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM users WHERE name = $1 AND age > (SELECT AVG(age) FROM users WHERE country = $2) LIMIT 10`, sql)
+		assert.EqualValues(t, []interface{}{"amirreza", "IR"}, args)
+	})
+	t.Run("where in", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			Table("posts").
+			SetSelect().
+			WhereIn("id", NewQueryBuilder[Dummy](nil).Table("comments").Select("post_id").Where("approved", true)).
+			Where("published", true).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM posts WHERE id IN (SELECT post_id FROM comments WHERE approved = $1) AND published = $2`, sql)
+		assert.EqualValues(t, []interface{}{true, true}, args)
+	})
+	t.Run("where exists", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			Table("posts").
+			SetSelect().
+			Where("published", true).
+			WhereExists(NewQueryBuilder[Dummy](nil).Table("comments").Where(Raw("comments.post_id = posts.id")).Where("approved", true)).
+			WhereNotExists(NewQueryBuilder[Dummy](nil).Table("reports").Where(Raw("reports.post_id = posts.id AND reason = ?", "spam"))).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM posts WHERE published = $1 AND EXISTS (SELECT * FROM comments WHERE comments.post_id = posts.id AND approved = $2) AND NOT EXISTS (SELECT * FROM reports WHERE reports.post_id = posts.id AND reason = $3)`, sql)
+		assert.EqualValues(t, []interface{}{true, true, "spam"}, args)
+	})
+	t.Run("selected column", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			Table("posts").
+			Select("id").
+			SelectQuery(NewQueryBuilder[Dummy](nil).Table("comments").Select("COUNT(*)").Where(Raw("comments.post_id = posts.id AND approved = ?", true)), "comments_count").
+			Select("title").
+			Where("id", 1).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT id,(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND approved = $1) AS comments_count,title FROM posts WHERE id = $2`, sql)
+		assert.EqualValues(t, []interface{}{true, 1}, args)
+	})
+	t.Run("join", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			Table("posts").
+			JoinQuery(JoinTypeLeft, NewQueryBuilder[Dummy](nil).Table("comments").Select("post_id", "COUNT(*) AS total").Where("approved", true).GroupBy("post_id"), "c", On("c.post_id", "posts.id")).
+			Where("posts.published", true).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM posts LEFT JOIN (SELECT post_id,COUNT(*) AS total FROM comments WHERE approved = $1 GROUP BY post_id) AS c ON c.post_id = posts.id WHERE posts.published = $2`, sql)
+		assert.EqualValues(t, []interface{}{true, true}, args)
+	})
+	t.Run("join without alias", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("posts").
+			JoinQuery(JoinTypeInner, NewQueryBuilder[Dummy](nil).Table("comments"), "", On("c.post_id", "posts.id")).
+			ToSql()
+		assert.Error(t, err)
+	})
+}
+
+func TestJoinConditions(t *testing.T) {
+	t.Run("compound on", func(t *testing.T) {
+		sql, args, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.PostgreSQL).
+			Table("posts").
+			Alias("p").
+			Select("p.id", "a.name AS author__name").
+			JoinOn(JoinTypeLeft, "authors", "a", On("a.id", "p.author_id").On("a.tenant_id", "p.tenant_id").Where("a.active", true).OrOn("a.id", "p.editor_id")).
+			Where("p.published", true).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT p.id,a.name AS author__name FROM posts AS p LEFT JOIN authors AS a ON a.id = p.author_id AND a.tenant_id = p.tenant_id AND a.active = $1 OR a.id = p.editor_id WHERE p.published = $2`, sql)
+		assert.EqualValues(t, []interface{}{true, true}, args)
+	})
+	t.Run("self join", func(t *testing.T) {
+		sql, _, err := NewQueryBuilder[Dummy](nil).
+			Table("employees").
+			Alias("e").
+			Select("e.name", "m.name AS manager__name").
+			JoinOn(JoinTypeInner, "employees", "m", On("m.id", "e.manager_id")).
+			ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT e.name,m.name AS manager__name FROM employees AS e INNER JOIN employees AS m ON m.id = e.manager_id`, sql)
+	})
+	t.Run("invalid condition", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).
+			Table("posts").
+			JoinOn(JoinTypeInner, "authors", "", On("authors.id", "posts.author_id").Where(1)).
+			ToSql()
+		assert.Error(t, err)
+	})
+}
+
+func TestLocking(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect *Dialect
+		q       func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy]
+		sql     string
+	}{
+		{
+			name:    "postgres for update skip locked",
+			dialect: Dialects.PostgreSQL,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.Where("status", "pending").OrderBy("id", ASC).Limit(1).ForUpdate().SkipLocked()
+			},
+			sql: `SELECT * FROM jobs WHERE status = $1 ORDER BY id ASC LIMIT 1 FOR UPDATE SKIP LOCKED`,
+		},
+		{
+			name:    "mysql for share nowait",
+			dialect: Dialects.MySQL,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.Where("id", 1).NoWait().ForShare()
+			},
+			sql: `SELECT * FROM jobs WHERE id = ? FOR SHARE NOWAIT`,
+		},
+		{
+			name:    "mysql for share",
+			dialect: Dialects.MySQL,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.Where("id", 1).ForShare()
+			},
+			sql: `SELECT * FROM jobs WHERE id = ? LOCK IN SHARE MODE`,
+		},
+		{
+			name:    "postgres for share",
+			dialect: Dialects.PostgreSQL,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.Where("id", 1).ForShare()
+			},
+			sql: `SELECT * FROM jobs WHERE id = $1 FOR SHARE`,
+		},
+		{
+			name:    "sqlite ignores locks",
+			dialect: Dialects.SQLite3,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.Where("id", 1).ForUpdate().SkipLocked()
+			},
+			sql: `SELECT * FROM jobs WHERE id = ?`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.q(NewQueryBuilder[Dummy](nil).SetDialect(tt.dialect).Table("jobs")).ToSql()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+		})
+	}
+	t.Run("skip locked without lock", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("jobs").SkipLocked().ToSql()
+		assert.Error(t, err)
+	})
+	t.Run("skip locked and nowait", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("jobs").ForUpdate().SkipLocked().NoWait().ToSql()
+		assert.Error(t, err)
+	})
+	t.Run("locking distinct", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("jobs").Select("queue").Distinct().ForUpdate().ToSql()
+		assert.Error(t, err)
+	})
+}
+
+func TestWhereJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect *Dialect
+		q       func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy]
+		sql     string
+		args    []interface{}
+	}{
+		{
+			name:    "postgres",
+			dialect: Dialects.PostgreSQL,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.Where("id", 1).WhereJSON("meta->plan", "pro")
+			},
+			sql:  `SELECT * FROM users WHERE id = $1 AND meta->>'plan' = $2`,
+			args: []interface{}{1, "pro"},
+		},
+		{
+			name:    "postgres nested keys",
+			dialect: Dialects.PostgreSQL,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.WhereJSON("meta->limits->seats", ">", 3)
+			},
+			sql:  `SELECT * FROM users WHERE meta->'limits'->>'seats' > $1`,
+			args: []interface{}{3},
+		},
+		{
+			name:    "mysql",
+			dialect: Dialects.MySQL,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.WhereJSON("meta->limits->seats", ">", 3).OrWhereJSON("meta->plan", "pro")
+			},
+			sql:  `SELECT * FROM users WHERE JSON_UNQUOTE(JSON_EXTRACT(meta, '$."limits"."seats"')) > ? OR JSON_UNQUOTE(JSON_EXTRACT(meta, '$."plan"')) = ?`,
+			args: []interface{}{3, "pro"},
+		},
+		{
+			name:    "sqlite",
+			dialect: Dialects.SQLite3,
+			q: func(q *QueryBuilder[Dummy]) *QueryBuilder[Dummy] {
+				return q.WhereJSON("meta->plan", In, "pro", "team")
+			},
+			sql:  `SELECT * FROM users WHERE meta->>'plan' IN (?,?)`,
+			args: []interface{}{"pro", "team"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.q(NewQueryBuilder[Dummy](nil).SetDialect(tt.dialect).Table("users").SetSelect()).ToSql()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+	t.Run("path without key", func(t *testing.T) {
+		_, _, err := NewQueryBuilder[Dummy](nil).Table("users").SetSelect().WhereJSON("meta", "pro").ToSql()
+		assert.Error(t, err)
+	})
+}
+
+func TestPlaceholders(t *testing.T) {
+	t.Run("next generates placeholders in order without regenerating each time", func(t *testing.T) {
+		generated := 0
+		ph := newPlaceholders(func(n int) []string {
+			generated += n
+			return postgresPlaceholder(n)
+		})
+		var phs []string
+		for i := 0; i < 100; i++ {
+			phs = append(phs, ph.next(1)...)
+		}
+		phs = append(phs, ph.next(3)...)
+		assert.Equal(t, postgresPlaceholder(103), phs)
+		assert.Less(t, generated, 4*103)
+	})
+}
@@ -1,40 +1,44 @@
 package orm
 
 import (
-	"database/sql"
-	"database/sql/driver"
 	"fmt"
 	"reflect"
 )
 
-func getConnectionFor(e Entity) *connection {
+func getConnectionFor(e Entity) (*connection, error) {
 	configurator := newEntityConfigurator()
 	e.ConfigureEntity(configurator)
 
 	if len(globalConnections) > 1 && (configurator.connection == "" || configurator.table == "") {
-		panic("need table and DB name when having more than 1 DB registered")
+		return nil, fmt.Errorf("need table and DB name when having more than 1 DB registered: %w", ErrNoConnection)
 	}
 	if len(globalConnections) == 1 {
 		for _, db := range globalConnections {
-			return db
+			return db, nil
 		}
 	}
 	if db, exists := globalConnections[fmt.Sprintf("%s", configurator.connection)]; exists {
-		return db
+		return db, nil
 	}
-	panic("no db found")
+	return nil, ErrNoConnection
 }
 
-func getSchemaFor(e Entity) *schema {
+func getSchemaFor(e Entity) (*schema, error) {
 	configurator := newEntityConfigurator()
-	c := getConnectionFor(e)
+	c, err := getConnectionFor(e)
+	if err != nil {
+		return nil, err
+	}
 	e.ConfigureEntity(configurator)
 	s := c.getSchema(configurator.table)
 	if s == nil {
-		s = schemaOfHeavyReflectionStuff(e)
+		s, err = schemaOfHeavyReflectionStuff(e)
+		if err != nil {
+			return nil, err
+		}
 		c.setSchema(e, s)
 	}
-	return s
+	return s, nil
 }
 
 type schema struct {
@@ -47,17 +51,14 @@ type schema struct {
 	columnConstraints []*FieldConfigurator
 }
 
-func (s *schema) getField(sf reflect.StructField) *field {
-	for _, f := range s.fields {
-		if sf.Name == f.Name {
-			return f
-		}
-	}
-	return nil
-}
-
+// getDialect returns dialect of schema connection, schemas returned from getSchemaFor
+// always have a connection, for other ones it returns nil.
 func (s *schema) getDialect() *Dialect {
-	return GetConnection(s.Connection).Dialect
+	c, err := s.getConnection()
+	if err != nil {
+		return nil
+	}
+	return c.Dialect
 }
 func (s *schema) Columns(withPK bool) []string {
 	var cols []string
@@ -68,7 +69,7 @@ func (s *schema) Columns(withPK bool) []string {
 		if !withPK && field.IsPK {
 			continue
 		}
-		if s.getDialect().AddTableNameInSelectColumns {
+		if d := s.getDialect(); d != nil && d.AddTableNameInSelectColumns {
 			cols = append(cols, s.Table+"."+field.Name)
 		} else {
 			cols = append(cols, field.Name)
@@ -101,83 +102,56 @@ func genericFieldsOf(obj Entity) []*field {
 	var ec EntityConfigurator
 	obj.ConfigureEntity(&ec)
 
-	var fms []*field
-	for i := 0; i < t.NumField(); i++ {
-		ft := t.Field(i)
-		fm := fieldMetadata(ft, ec.columnConstraints)
-		fms = append(fms, fm...)
-	}
-	return fms
+	return fieldsOfType(t, ec.columnConstraints)
 }
 
-func valuesOfField(vf reflect.Value) []interface{} {
-	var values []interface{}
-	if vf.Type().Kind() == reflect.Struct || vf.Type().Kind() == reflect.Ptr {
-		t := vf.Type()
-		if vf.Type().Kind() == reflect.Ptr {
-			t = vf.Type().Elem()
-		}
-		if !t.Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem()) {
-			// go into
-			// it does not implement driver.Valuer interface
-			for i := 0; i < vf.NumField(); i++ {
-				vif := vf.Field(i)
-				values = append(values, valuesOfField(vif)...)
-			}
-		} else {
-			values = append(values, vf.Interface())
-		}
-	} else {
-		values = append(values, vf.Interface())
-	}
-	return values
-}
-func genericValuesOf(o Entity, withPK bool) []interface{} {
-	t := reflect.TypeOf(o)
+// genericValuesOf returns values of o for columns returned from s.Columns(withPK), fields
+// inside nil embedded or nested struct pointers are nil.
+func genericValuesOf(s *schema, o Entity, withPK bool) []interface{} {
 	v := reflect.ValueOf(o)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	for v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
-	fields := getSchemaFor(o).fields
-	pkIdx := -1
-	for i, field := range fields {
-		if field.IsPK {
-			pkIdx = i
-		}
-
-	}
-
 	var values []interface{}
-
-	for i := 0; i < t.NumField(); i++ {
-		if !withPK && i == pkIdx {
+	for _, field := range s.fields {
+		if field.Virtual {
 			continue
 		}
-		if fields[i].Virtual {
+		if !withPK && field.IsPK {
 			continue
 		}
-		vf := v.Field(i)
-		values = append(values, valuesOfField(vf)...)
+		fv, ok := fieldByPath(v, field.Path, false)
+		if !ok {
+			values = append(values, nil)
+			continue
+		}
+		if field.Converter != nil {
+			values = append(values, convertedValue{c: field.Converter, v: fv})
+			continue
+		}
+		if field.JSON {
+			values = append(values, jsonValue{v: fv})
+			continue
+		}
+		values = append(values, fv.Interface())
 	}
 	return values
 }
 
-func genericSetPkValue(obj Entity, value interface{}) {
-	genericSet(obj, getSchemaFor(obj).pkName(), value)
+func genericSetPkValue(s *schema, obj Entity, value interface{}) {
+	genericSet(s, obj, s.pkName(), value)
 }
 
-func genericGetPKValue(obj Entity) interface{} {
-	t := reflect.TypeOf(obj)
+func genericGetPKValue(s *schema, obj Entity) interface{} {
 	val := reflect.ValueOf(obj)
-	if t.Kind() == reflect.Ptr {
+	for val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
-
-	fields := getSchemaFor(obj).fields
-	for i, field := range fields {
+	for _, field := range s.fields {
 		if field.IsPK {
-			return val.Field(i).Interface()
+			if fv, ok := fieldByPath(val, field.Path, false); ok {
+				return fv.Interface()
+			}
 		}
 	}
 	return ""
@@ -208,42 +182,31 @@ func (s *schema) deletedAt() *field {
 	}
 	return nil
 }
-func pointersOf(v reflect.Value) map[string]interface{} {
-	m := map[string]interface{}{}
-	actualV := v
-	for actualV.Type().Kind() == reflect.Ptr {
-		actualV = actualV.Elem()
-	}
-	for i := 0; i < actualV.NumField(); i++ {
-		f := actualV.Field(i)
-		if (f.Type().Kind() == reflect.Struct || f.Type().Kind() == reflect.Ptr) && !f.Type().Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem()) {
-			fm := pointersOf(f)
-			for k, p := range fm {
-				m[k] = p
-			}
-		} else {
-			fm := fieldMetadata(actualV.Type().Field(i), nil)[0]
-			m[fm.Name] = actualV.Field(i)
-		}
-	}
 
-	return m
-}
-func genericSet(obj Entity, name string, value interface{}) {
-	n2p := pointersOf(reflect.ValueOf(obj))
-	var val interface{}
-	for k, v := range n2p {
-		if k == name {
-			val = v
+// genericSet sets field of obj that has column name to value, obj should be a pointer
+// and it does nothing if there is no such field.
+func genericSet(s *schema, obj Entity, name string, value interface{}) {
+	for _, field := range s.fields {
+		if field.Name != name || field.Virtual {
+			continue
+		}
+		v := reflect.ValueOf(obj)
+		if v.Kind() != reflect.Ptr {
+			return
 		}
+		if fv, ok := fieldByPath(v.Elem(), field.Path, true); ok {
+			fv.Set(reflect.ValueOf(value))
+		}
+		return
 	}
-	val.(reflect.Value).Set(reflect.ValueOf(value))
 }
-func schemaOfHeavyReflectionStuff(v Entity) *schema {
+func schemaOfHeavyReflectionStuff(v Entity) (*schema, error) {
 	userEntityConfigurator := newEntityConfigurator()
 	v.ConfigureEntity(userEntityConfigurator)
 	for _, relation := range userEntityConfigurator.resolveRelations {
-		relation()
+		if err := relation(); err != nil {
+			return nil, err
+		}
 	}
 	schema := &schema{}
 	if userEntityConfigurator.connection != "" {
@@ -252,48 +215,59 @@ func schemaOfHeavyReflectionStuff(v Entity) *schema {
 	if userEntityConfigurator.table != "" {
 		schema.Table = userEntityConfigurator.table
 	} else {
-		panic("you need to have table name for getting schema.")
+		return nil, fmt.Errorf("you need to have table name for getting schema of %T", v)
 	}
 
 	schema.columnConstraints = userEntityConfigurator.columnConstraints
+	if err := checkFieldTags(reflect.TypeOf(v)); err != nil {
+		return nil, err
+	}
+	if err := checkEncryption(schema.columnConstraints); err != nil {
+		return nil, err
+	}
 	if schema.Connection == "" {
 		schema.Connection = "default"
 	}
 	if schema.fields == nil {
 		schema.fields = genericFieldsOf(v)
 	}
+	bindEncryptedColumns(schema.Table, schema.fields)
 	if schema.getPK == nil {
-		schema.getPK = genericGetPKValue
+		schema.getPK = func(o Entity) interface{} {
+			return genericGetPKValue(schema, o)
+		}
 	}
 
 	if schema.setPK == nil {
-		schema.setPK = genericSetPkValue
+		schema.setPK = func(o Entity, value interface{}) {
+			genericSetPkValue(schema, o, value)
+		}
 	}
 
 	schema.relations = userEntityConfigurator.relations
 
-	return schema
+	return schema, nil
 }
 
 func (s *schema) getTable() string {
 	return s.Table
 }
 
-func (s *schema) getSQLDB() *sql.DB {
-	return s.getConnection().DB
-}
-
-func (s *schema) getConnection() *connection {
+func (s *schema) getConnection() (*connection, error) {
+	if s == nil {
+		// query builders created without an entity have no schema.
+		return nil, fmt.Errorf("query has no entity to find its connection: %w", ErrNoConnection)
+	}
 	if len(globalConnections) > 1 && (s.Connection == "" || s.Table == "") {
-		panic("need table and DB name when having more than 1 DB registered")
+		return nil, fmt.Errorf("need table and DB name when having more than 1 DB registered: %w", ErrNoConnection)
 	}
 	if len(globalConnections) == 1 {
 		for _, db := range globalConnections {
-			return db
+			return db, nil
 		}
 	}
 	if db, exists := globalConnections[fmt.Sprintf("%s", s.Connection)]; exists {
-		return db
+		return db, nil
 	}
-	panic("no db found")
+	return nil, ErrNoConnection
 }
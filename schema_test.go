@@ -2,7 +2,9 @@ package orm
 
 import (
 	"database/sql"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -47,11 +49,124 @@ func TestGenericFieldsOf(t *testing.T) {
 	})
 }
 
+type TaggedObject struct {
+	UserID  int64  `orm:"pk"`
+	Name    string `orm:"column:user_name;index"`
+	Bio     string `orm:"nullable"`
+	Email   string `orm:"column:email_address"`
+	Joined  string `orm:"createdAt"`
+	Scratch string `orm:"ignore"`
+}
+
+func (o TaggedObject) ConfigureEntity(e *EntityConfigurator) {
+	e.Table("tagged_objects")
+	e.Field("Email").ColumnName("mail")
+	e.Field("Bio").Nullable(false)
+}
+
+type BadlyTaggedObject struct {
+	ID   int64
+	Name string `orm:"primarykey"`
+}
+
+func (o BadlyTaggedObject) ConfigureEntity(e *EntityConfigurator) {
+	e.Table("badly_tagged_objects")
+}
+
+type linkedObject struct {
+	ID   int64
+	Next *linkedObject
+}
+
+type ignoringObject struct {
+	ID  int64
+	Bad *BadlyTaggedObject `orm:"ignore"`
+}
+
+func TestFieldTags(t *testing.T) {
+	t.Run("tags are parsed into field metadata", func(t *testing.T) {
+		fs := genericFieldsOf(&TaggedObject{})
+		assert.Len(t, fs, 6)
+		assert.Equal(t, "user_id", fs[0].Name)
+		assert.True(t, fs[0].IsPK)
+		assert.Equal(t, "user_name", fs[1].Name)
+		assert.True(t, fs[1].Index)
+		assert.False(t, fs[2].Index)
+		_, err := schemaOfHeavyReflectionStuff(&TaggedObject{})
+		assert.NoError(t, err)
+		assert.Equal(t, "joined", fs[4].Name)
+		assert.True(t, fs[4].IsCreatedAt)
+		assert.True(t, fs[5].Virtual)
+	})
+	t.Run("ConfigureEntity takes precedence over tags", func(t *testing.T) {
+		fs := genericFieldsOf(&TaggedObject{})
+		assert.False(t, fs[2].Nullable)
+		assert.Equal(t, "mail", fs[3].Name)
+	})
+	t.Run("unknown tag option is an error", func(t *testing.T) {
+		_, err := schemaOfHeavyReflectionStuff(&BadlyTaggedObject{})
+		assert.ErrorContains(t, err, "primarykey")
+	})
+	t.Run("self referencing and ignored fields are checked once", func(t *testing.T) {
+		assert.NoError(t, checkFieldTags(reflect.TypeOf(linkedObject{})))
+		assert.NoError(t, checkFieldTags(reflect.TypeOf(ignoringObject{})))
+	})
+	t.Run("parse options", func(t *testing.T) {
+		tag, err := parseFieldTag(" column:name ; NULLABLE;")
+		assert.NoError(t, err)
+		assert.Equal(t, fieldTag{column: "name", nullable: true}, tag)
+		_, err = parseFieldTag("pk:true")
+		assert.Error(t, err)
+		_, err = parseFieldTag("column:")
+		assert.Error(t, err)
+		tag, err = parseFieldTag("Index;column:email")
+		assert.NoError(t, err)
+		assert.Equal(t, fieldTag{column: "email", index: true}, tag)
+		_, err = parseFieldTag("index:true")
+		assert.Error(t, err)
+	})
+}
+
+type Account struct {
+	*Timestamps
+	Name string
+	ID   int64
+}
+
+func (a Account) ConfigureEntity(e *EntityConfigurator) {
+	e.Table("accounts")
+}
+
+func TestFieldPaths(t *testing.T) {
+	s, err := schemaOfHeavyReflectionStuff(&Account{})
+	assert.NoError(t, err)
+	var paths [][]int
+	for _, f := range s.fields {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, [][]int{{0, 0}, {0, 1}, {0, 2}, {1}, {2}}, paths)
+
+	a := &Account{Name: "main", ID: 7}
+	assert.Equal(t, []interface{}{nil, nil, nil, "main"}, genericValuesOf(s, a, false))
+	assert.Equal(t, int64(7), genericGetPKValue(s, a))
+
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	genericSet(s, a, "created_at", now)
+	assert.NotNil(t, a.Timestamps)
+	assert.Equal(t, now, a.CreatedAt)
+	assert.Equal(t, []interface{}{now, sql.NullTime{}, sql.NullTime{}, "main", int64(7)}, genericValuesOf(s, a, true))
+
+	genericSetPkValue(s, a, int64(8))
+	assert.Equal(t, int64(8), a.ID)
+}
+
 func TestGenericValuesOf(t *testing.T) {
 	t.Run("values of", func(t *testing.T) {
 
 		setup(t)
-		vs := genericValuesOf(Object{}, true)
+		s, err := getSchemaFor(Object{})
+		assert.NoError(t, err)
+		vs := genericValuesOf(s, Object{}, true)
 		assert.Len(t, vs, 5)
 	})
 }
@@ -67,3 +182,69 @@ func TestEntityConfigurator(t *testing.T) {
 	})
 
 }
+
+type NoTable struct {
+	ID int64
+}
+
+func (n NoTable) ConfigureEntity(e *EntityConfigurator) {}
+
+type NoIntermediateTable struct {
+	ID int64
+}
+
+func (n NoIntermediateTable) ConfigureEntity(e *EntityConfigurator) {
+	e.Table("no_intermediates").BelongsToMany(Object{}, BelongsToManyConfig{})
+}
+
+type OtherConnectionObject struct {
+	ID int64
+}
+
+func (o OtherConnectionObject) ConfigureEntity(e *EntityConfigurator) {
+	e.Table("others").Connection("other")
+}
+
+func TestSchemaErrors(t *testing.T) {
+	t.Run("entity without table", func(t *testing.T) {
+		_, err := schemaOfHeavyReflectionStuff(NoTable{})
+		assert.Error(t, err)
+	})
+
+	t.Run("belongs to many without intermediate table", func(t *testing.T) {
+		_, err := schemaOfHeavyReflectionStuff(NoIntermediateTable{})
+		assert.Error(t, err)
+	})
+
+	t.Run("setup with misconfigured entity", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", ":memory:")
+		assert.NoError(t, err)
+		err = SetupConnections(ConnectionConfig{
+			Name:     "default",
+			DB:       db,
+			Dialect:  Dialects.SQLite3,
+			Entities: []Entity{NoTable{}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("no connection found", func(t *testing.T) {
+		setup(t)
+		globalConnections["second"] = globalConnections["default"]
+		defer delete(globalConnections, "second")
+
+		_, err := getSchemaFor(OtherConnectionObject{})
+		assert.ErrorIs(t, err, ErrNoConnection)
+
+		_, err = Query[OtherConnectionObject]().WherePK(1).First().Get()
+		assert.ErrorIs(t, err, ErrNoConnection)
+
+		assert.ErrorIs(t, Insert(&OtherConnectionObject{}), ErrNoConnection)
+	})
+
+	t.Run("query without schema", func(t *testing.T) {
+		setup(t)
+		_, err := NewQueryBuilder[Dummy](nil).SetDialect(Dialects.SQLite3).Table("users").Count()
+		assert.ErrorIs(t, err, ErrNoConnection)
+	})
+}
@@ -0,0 +1,78 @@
+package orm
+
+import (
+	"iter"
+	"reflect"
+)
+
+// Iter is a finisher, it runs select query generated by QueryBuilder and returns an iterator that
+// scans rows one by one, so memory stays bounded no matter how many rows query returns. Query errors
+// are yielded as the last pair, rows are closed when loop ends. Connection used by Iter stays busy
+// until loop ends, use Chunk if you need to run other queries in a transaction while iterating.
+func (q *QueryBuilder[OUTPUT]) Iter() iter.Seq2[OUTPUT, error] {
+	return func(yield func(OUTPUT, error) bool) {
+		rows, err := q.queryRows()
+		if err != nil {
+			yield(*new(OUTPUT), err)
+			return
+		}
+		defer rows.Close()
+		cts, err := rows.ColumnTypes()
+		if err != nil {
+			yield(*new(OUTPUT), err)
+			return
+		}
+		b := newBinderFor[OUTPUT](q.schema)
+		t := reflect.TypeOf((*OUTPUT)(nil)).Elem()
+		for rows.Next() {
+			v, err := b.bindRow(rows, cts, t)
+			if err != nil {
+				yield(*new(OUTPUT), err)
+				return
+			}
+			if !yield(v.Interface().(OUTPUT), nil) {
+				return
+			}
+		}
+		if err = rows.Err(); err != nil {
+			yield(*new(OUTPUT), err)
+		}
+	}
+}
+
+// Each is a finisher, it calls fn for every row query returns using Iter, it stops
+// at first error either from query or fn and returns it.
+func (q *QueryBuilder[OUTPUT]) Each(fn func(OUTPUT) error) error {
+	for item, err := range q.Iter() {
+		if err != nil {
+			return err
+		}
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Chunk is a finisher, it fetches rows in chunks of given size ordered by primary key and calls
+// fn with each chunk. Each chunk is fetched using primary key of last row of previous one instead of
+// Offset, so changing rows inside fn does not make rows get skipped or repeated.
+func (q *QueryBuilder[OUTPUT]) Chunk(size int, fn func([]OUTPUT) error) error {
+	var cursor string
+	for {
+		page, err := q.CursorPaginate(cursor, size)
+		if err != nil {
+			return err
+		}
+		if len(page.Items) == 0 {
+			return nil
+		}
+		if err = fn(page.Items); err != nil {
+			return err
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,78 @@
+package orm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golobby/orm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreaming(t *testing.T) {
+	setupPosts := func(t *testing.T) {
+		assert.NoError(t, setup())
+		for _, body := range []string{"a", "b", "c", "d", "e"} {
+			assert.NoError(t, orm.Save(&Post{BodyText: body}))
+		}
+	}
+
+	t.Run("iter", func(t *testing.T) {
+		setupPosts(t)
+		var ids []int64
+		for post, err := range orm.Query[Post]().Where("id", ">", 1).Iter() {
+			assert.NoError(t, err)
+			ids = append(ids, post.ID)
+			if len(ids) == 3 {
+				break
+			}
+		}
+		assert.Equal(t, []int64{2, 3, 4}, ids)
+	})
+
+	t.Run("iter error", func(t *testing.T) {
+		setupPosts(t)
+		var errs int
+		for _, err := range orm.Query[Post]().Table("unknown").Iter() {
+			assert.Error(t, err)
+			errs++
+		}
+		assert.Equal(t, 1, errs)
+	})
+
+	t.Run("each", func(t *testing.T) {
+		setupPosts(t)
+		var bodies []string
+		err := orm.Query[Post]().Each(func(p Post) error {
+			bodies = append(bodies, p.BodyText)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, bodies)
+
+		errStop := errors.New("stop")
+		err = orm.Query[Post]().Each(func(p Post) error {
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+	})
+
+	t.Run("chunk with mutation", func(t *testing.T) {
+		setupPosts(t)
+		var chunks [][]int64
+		err := orm.Query[Post]().Where("body", "!=", "deleted").Chunk(2, func(posts []Post) error {
+			chunks = append(chunks, postIDs(posts))
+			for _, p := range posts {
+				if _, err := orm.Query[Post]().Where("id", p.ID).Set("body", "deleted").Update(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, [][]int64{{1, 2}, {3, 4}, {5}}, chunks)
+
+		count, err := orm.Query[Post]().Where("body", "deleted").Count()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 5, count)
+	})
+}
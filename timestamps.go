@@ -2,6 +2,8 @@ package orm
 
 import (
 	"database/sql"
+	"reflect"
+	"time"
 )
 
 type Timestamps struct {
@@ -9,3 +11,17 @@ type Timestamps struct {
 	UpdatedAt sql.NullTime
 	DeletedAt sql.NullTime
 }
+
+// timestampFor returns t in type of timestamp field f, which can be sql.NullTime, Null[time.Time],
+// time.Time or *time.Time.
+func timestampFor(f *field, t time.Time) interface{} {
+	switch f.Type {
+	case reflect.TypeOf(Null[time.Time]{}):
+		return NewNull(t)
+	case timeType:
+		return t
+	case reflect.PointerTo(timeType):
+		return &t
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
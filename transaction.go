@@ -0,0 +1,47 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type txKey struct {
+	connection string
+}
+
+// Transaction runs fn inside a database transaction on connection with given name, queries that
+// use ctx passed to fn, like a QueryBuilder with WithContext(ctx), run inside the transaction.
+// Transaction is committed when fn returns nil and rolled back when it returns an error or panics,
+// calling Transaction with a ctx that already has a transaction on same connection reuses it.
+func Transaction(ctx context.Context, connectionName string, fn func(ctx context.Context) error) error {
+	conn, exists := globalConnections[connectionName]
+	if !exists {
+		return ErrNoConnection
+	}
+	if conn.txFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	tx, err := conn.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return conn.Dialect.translateError(err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err = fn(context.WithValue(ctx, txKey{connection: conn.Name}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+	return conn.Dialect.translateError(tx.Commit())
+}
+
+func (c *connection) txFromContext(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txKey{connection: c.Name}).(*sql.Tx)
+	return tx
+}